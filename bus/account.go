@@ -0,0 +1,418 @@
+package bus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/ledgerhq/satstack/types"
+)
+
+// GetAccountSummary returns the totals received and sent by the account made
+// of the given descriptors, along with its current balance and number of
+// transactions. The descriptors must be imported in the wallet.
+//
+// Totals are computed from the net effect of each wallet transaction on the
+// account: the value of the account outputs it creates, minus the value of
+// the account outputs it spends. A positive net is counted as received, and
+// a negative one as sent, fees included. Change outputs are therefore never
+// counted as received, since they only offset the inputs of the spending
+// transaction.
+//
+// Conflicted transactions are ignored, while unconfirmed ones are included.
+//
+// The totals are cached by account, and only computed again once the wallet
+// transactions change, since this requires fetching all of them.
+func (b *Bus) GetAccountSummary(descriptors []string) (*types.AccountSummary, error) {
+	addresses, err := b.importedAddresses(descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		owned[address] = true
+	}
+
+	result, err := b.listSinceBlock(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []btcjson.ListTransactionsResult
+	for _, entry := range result.Transactions {
+		if entry.Confirmations >= 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	// The derived addresses grow with the imported range of the descriptors.
+	key := fmt.Sprintf("%s/%d", strings.Join(descriptors, " "), len(addresses))
+	fingerprint := entriesFingerprint(entries)
+
+	summary, ok := b.cachedAccountSummary(key, fingerprint)
+	if !ok {
+		txs, err := b.walletTransactions(entries)
+		if err != nil {
+			return nil, err
+		}
+
+		summary = accountTotals(owned, txs)
+		b.cacheAccountSummary(key, fingerprint, summary)
+	}
+
+	balance, err := b.GetBalance(addresses, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Balance = *balance
+
+	return &summary, nil
+}
+
+// accountTotals returns the summary of the account owning the given
+// addresses, without its balance, from the wallet transactions.
+func accountTotals(owned map[string]bool, txs []types.Transaction) types.AccountSummary {
+	// Index the account outputs first, since the transactions spending them
+	// are not necessarily listed after the ones creating them.
+	outputs := make(types.UTXOs)
+	for _, tx := range txs {
+		for _, output := range tx.Outputs {
+			if !owned[output.Address] || output.Value == nil || output.OutputIndex == nil {
+				continue
+			}
+
+			outputs[types.OutputIdentifier{Hash: tx.Hash, Index: *output.OutputIndex}] = types.UTXOData{
+				Value:   *output.Value,
+				Address: output.Address,
+			}
+		}
+	}
+
+	var summary types.AccountSummary
+
+	for _, tx := range txs {
+		var credit, debit btcutil.Amount
+
+		for _, output := range tx.Outputs {
+			if output.OutputIndex == nil {
+				continue
+			}
+
+			if utxo, ok := outputs[types.OutputIdentifier{Hash: tx.Hash, Index: *output.OutputIndex}]; ok {
+				credit += utxo.Value
+			}
+		}
+
+		for _, input := range tx.Inputs {
+			if input.OutputIndex == nil {
+				continue // coinbase
+			}
+
+			if utxo, ok := outputs[types.OutputIdentifier{Hash: input.OutputHash, Index: *input.OutputIndex}]; ok {
+				debit += utxo.Value
+			}
+		}
+
+		if credit == 0 && debit == 0 {
+			continue // transaction of another account of the wallet
+		}
+
+		summary.TxCount++
+
+		if net := credit - debit; net > 0 {
+			summary.Received += net
+		} else {
+			summary.Sent -= net
+		}
+	}
+
+	return summary
+}
+
+// accountSummaryEntry is a cached account summary, without its balance,
+// along with the fingerprint of the wallet transactions it was computed from.
+type accountSummaryEntry struct {
+	fingerprint string
+	summary     types.AccountSummary
+}
+
+// entriesFingerprint returns a digest of the given wallet transaction
+// entries, which changes whenever a transaction is added, confirmed,
+// reorganized out or conflicted.
+func entriesFingerprint(entries []btcjson.ListTransactionsResult) string {
+	h := sha256.New()
+	for _, entry := range entries {
+		h.Write([]byte(entry.TxID))
+		h.Write([]byte(entry.BlockHash))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedAccountSummary returns the cached summary of the account made of the
+// given descriptors, if it was computed from the same wallet transactions.
+func (b *Bus) cachedAccountSummary(key string, fingerprint string) (types.AccountSummary, bool) {
+	b.accountSummariesMu.Lock()
+	defer b.accountSummariesMu.Unlock()
+
+	entry, ok := b.accountSummaries[key]
+	if !ok || entry.fingerprint != fingerprint {
+		return types.AccountSummary{}, false
+	}
+
+	return entry.summary, true
+}
+
+// cacheAccountSummary stores the summary of the account made of the given
+// descriptors, replacing the previous one.
+func (b *Bus) cacheAccountSummary(key string, fingerprint string, summary types.AccountSummary) {
+	b.accountSummariesMu.Lock()
+	defer b.accountSummariesMu.Unlock()
+
+	if b.accountSummaries == nil {
+		b.accountSummaries = make(map[string]accountSummaryEntry)
+	}
+
+	b.accountSummaries[key] = accountSummaryEntry{fingerprint: fingerprint, summary: summary}
+}
+
+// GetTransactionsInRange returns the transactions of the account made of the
+// given descriptors, that were confirmed in blocks between startHeight and
+// endHeight (inclusive), ordered by height. This allows reconstructing the
+// history of a range of blocks, without rescanning the wallet.
+//
+// The end of the range is clamped to the chain tip, and the transactions are
+// those known by the wallet, so the range must have been scanned already.
+func (b *Bus) GetTransactionsInRange(descriptors []string, startHeight int64, endHeight int64) ([]types.Transaction, error) {
+	if startHeight < 0 || startHeight > endHeight {
+		return nil, fmt.Errorf("%w: range [%d, %d]", ErrInvalidHeight, startHeight, endHeight)
+	}
+
+	tip, err := b.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+
+	if endHeight > tip {
+		endHeight = tip
+	}
+
+	if startHeight > endHeight {
+		return []types.Transaction{}, nil
+	}
+
+	addresses, err := b.importedAddresses(descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		owned[address] = true
+	}
+
+	// listsinceblock lists the transactions of the blocks after the given
+	// one, so start from the parent of the first block of the range.
+	var since *chainhash.Hash
+	if startHeight > 0 {
+		since, err = b.mainClient.GetBlockHash(startHeight - 1)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
+		}
+	}
+
+	result, err := b.listSinceBlock(since)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []btcjson.ListTransactionsResult
+	for _, entry := range result.Transactions {
+		if entry.Confirmations <= 0 || entry.BlockHeight == nil {
+			continue // unconfirmed or conflicted
+		}
+
+		if height := int64(*entry.BlockHeight); height >= startHeight && height <= endHeight {
+			entries = append(entries, entry)
+		}
+	}
+
+	txs, err := b.walletTransactions(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	accountTxs := []types.Transaction{}
+	for _, tx := range txs {
+		if involvesAddresses(tx, owned) {
+			accountTxs = append(accountTxs, tx)
+		}
+	}
+
+	sort.SliceStable(accountTxs, func(i, j int) bool {
+		return accountTxs[i].Block.Height < accountTxs[j].Block.Height
+	})
+
+	return accountTxs, nil
+}
+
+// involvesAddresses reports whether a transaction spends from, or pays to,
+// any of the given addresses.
+func involvesAddresses(tx types.Transaction, addresses map[string]bool) bool {
+	for _, output := range tx.Outputs {
+		if addresses[output.Address] {
+			return true
+		}
+	}
+
+	for _, input := range tx.Inputs {
+		if addresses[input.Address] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importedAddresses returns the addresses derived from the given descriptors,
+// over the range they were imported with in the wallet.
+//
+// An account without any address is rejected with ErrInvalidDescriptor, since
+// an empty address list selects the whole wallet in some RPCs.
+func (b *Bus) importedAddresses(descriptors []string) ([]string, error) {
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("%w: no descriptors", ErrInvalidDescriptor)
+	}
+
+	imported, err := b.listDescriptors()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+
+	for _, desc := range descriptors {
+		canonicalDesc, err := GetCanonicalDescriptor(b.mainClient, normalizeDescriptor(desc))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+
+		descriptorRange, found := importedRange(imported, *canonicalDesc)
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrDescriptorNotImported, desc)
+		}
+
+		derived, err := b.mainClient.DeriveAddresses(*canonicalDesc, descriptorRange)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+
+		addresses = append(addresses, *derived...)
+	}
+
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("%w: no addresses derived from %v", ErrInvalidDescriptor, descriptors)
+	}
+
+	return addresses, nil
+}
+
+// importedRange returns the range over which a canonical descriptor is
+// imported in the wallet, or nil if it is not ranged, and whether it is
+// imported at all.
+func importedRange(imported *listDescriptorsResult, canonicalDesc string) (*btcjson.DescriptorRange, bool) {
+	for _, importedDesc := range imported.Descriptors {
+		if normalizeDescriptor(importedDesc.Descriptor) != normalizeDescriptor(canonicalDesc) {
+			continue
+		}
+
+		if len(importedDesc.Range) == 2 {
+			return &btcjson.DescriptorRange{Value: importedDesc.Range}, true
+		}
+
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// GetNextUnusedAddress returns the first address of an external descriptor
+// that never received funds, along with its index, to be used as a fresh
+// receive address.
+//
+// Addresses are derived in windows of DefaultGapLimit, so that the scan stops
+// early in accounts with few used addresses. Only addresses within the range
+// the descriptor was imported with are considered, since the wallet would not
+// detect payments to the ones beyond.
+func (b *Bus) GetNextUnusedAddress(externalDescriptor string) (string, int, error) {
+	info, err := b.mainClient.GetDescriptorInfo(normalizeDescriptor(externalDescriptor))
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+	}
+
+	if !info.IsRange {
+		return "", 0, fmt.Errorf("%w: %s", ErrDescriptorNotRanged, externalDescriptor)
+	}
+
+	imported, err := b.listDescriptors()
+	if err != nil {
+		return "", 0, err
+	}
+
+	descriptorRange, found := importedRange(imported, info.Descriptor)
+	if !found || descriptorRange == nil {
+		return "", 0, fmt.Errorf("%w: %s", ErrDescriptorNotImported, externalDescriptor)
+	}
+
+	used, err := b.usedAddresses()
+	if err != nil {
+		return "", 0, err
+	}
+
+	bounds := descriptorRange.Value.([]int) // as set by importedRange
+	last := bounds[1]
+
+	for start := bounds[0]; start <= last; start += DefaultGapLimit {
+		end := start + DefaultGapLimit - 1
+		if end > last {
+			end = last
+		}
+
+		derived, err := b.mainClient.DeriveAddresses(info.Descriptor,
+			&btcjson.DescriptorRange{Value: []int{start, end}})
+		if err != nil {
+			return "", 0, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+
+		for i, address := range *derived {
+			if !used[address] {
+				return address, start + i, nil
+			}
+		}
+	}
+
+	return "", 0, fmt.Errorf("%w: %s", ErrNoUnusedAddress, externalDescriptor)
+}
+
+// listDescriptors returns the descriptors imported in the wallet.
+func (b *Bus) listDescriptors() (*listDescriptorsResult, error) {
+	raw, err := b.mainClient.RawRequest("listdescriptors", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var imported listDescriptorsResult
+	if err := json.Unmarshal(raw, &imported); err != nil {
+		return nil, fmt.Errorf("unable to parse wallet descriptors: %w", err)
+	}
+
+	return &imported, nil
+}
@@ -0,0 +1,130 @@
+package bus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// emptyAccountNode returns a stub node where the given descriptor is
+// imported, but derives no addresses.
+func emptyAccountNode(t *testing.T, desc string) (*recordingNode, *Bus) {
+	node, client := newRecordingNode(t, map[string]string{
+		"getdescriptorinfo": `{"descriptor":"` + desc + `#aaaaaaaa","checksum":"aaaaaaaa","isrange":true}`,
+		"listdescriptors":   `{"wallet_name":"satstack","descriptors":[{"desc":"` + desc + `#aaaaaaaa","range":[0,0]}]}`,
+		"deriveaddresses":   `[]`,
+		"listunspent":       `[]`,
+		"listsinceblock":    `{"transactions":[],"removed":[],"lastblock":""}`,
+	})
+
+	return node, &Bus{mainClient: client}
+}
+
+func TestGetAccountSummaryWithoutAddresses(t *testing.T) {
+	const desc = "wpkh([8a2b9c1d/84'/1'/0']tpubA/0/*)"
+
+	tests := []struct {
+		name        string
+		descriptors []string
+	}{
+		{name: "no descriptors", descriptors: []string{}},
+		{name: "nil descriptors", descriptors: nil},
+		{name: "no derived addresses", descriptors: []string{desc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, b := emptyAccountNode(t, desc)
+
+			if _, err := b.GetAccountSummary(tt.descriptors); !errors.Is(err, ErrInvalidDescriptor) {
+				t.Fatalf("GetAccountSummary(%v) error = %v, want %v", tt.descriptors, err, ErrInvalidDescriptor)
+			}
+
+			if len(tt.descriptors) > 0 && len(node.calls("deriveaddresses")) != 1 {
+				t.Errorf("GetAccountSummary(%v) did not derive the addresses", tt.descriptors)
+			}
+
+			for _, method := range []string{"listsinceblock", "listunspent"} {
+				if calls := node.calls(method); len(calls) != 0 {
+					t.Errorf("GetAccountSummary(%v) called %s, which lists the whole wallet", tt.descriptors, method)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAccountSummaryCache(t *testing.T) {
+	const desc = "wpkh([8a2b9c1d/84'/1'/0']tpubA/0/*)"
+
+	params := &chaincfg.RegressionNetParams
+
+	address, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A transaction paying 1 BTC to the account, from outside the wallet.
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(btcutil.SatoshiPerBitcoin, pkScript))
+
+	var raw bytes.Buffer
+	if err := msgTx.Serialize(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	txid := msgTx.TxHash().String()
+
+	node, client := newRecordingNode(t, map[string]string{
+		"getdescriptorinfo": `{"descriptor":"` + desc + `#aaaaaaaa","checksum":"aaaaaaaa","isrange":true}`,
+		"listdescriptors":   `{"wallet_name":"satstack","descriptors":[{"desc":"` + desc + `#aaaaaaaa","range":[0,0]}]}`,
+		"deriveaddresses":   `["` + address.String() + `"]`,
+		"listunspent":       `[]`,
+		"listsinceblock": `{"transactions":[{"txid":"` + txid + `","address":"` + address.String() +
+			`","category":"receive","amount":1,"vout":0,"confirmations":0}],"removed":[],"lastblock":""}`,
+		"gettransaction": `{"txid":"` + txid + `","hex":"` + hex.EncodeToString(raw.Bytes()) +
+			`","amount":1,"confirmations":0,"time":0,"timereceived":0,"details":[]}`,
+	})
+
+	b := &Bus{mainClient: client, Params: params}
+
+	for i := 0; i < 2; i++ {
+		summary, err := b.GetAccountSummary([]string{desc})
+		if err != nil {
+			t.Fatalf("GetAccountSummary() error = %v", err)
+		}
+
+		if summary.Received != btcutil.SatoshiPerBitcoin || summary.Sent != 0 || summary.TxCount != 1 {
+			t.Errorf("GetAccountSummary() = %+v, want 1 BTC received in 1 transaction", summary)
+		}
+	}
+
+	if calls := node.calls("gettransaction"); len(calls) != 1 {
+		t.Errorf("GetAccountSummary() made %d gettransaction calls, want 1", len(calls))
+	}
+
+	// The confirmation of the transaction invalidates the cached summary.
+	node.setResult("listsinceblock", `{"transactions":[{"txid":"`+txid+`","address":"`+address.String()+
+		`","category":"receive","amount":1,"vout":0,"confirmations":1,"blockhash":"`+
+		(&chainhash.Hash{2}).String()+`","blockheight":101}],"removed":[],"lastblock":""}`)
+
+	if _, err := b.GetAccountSummary([]string{desc}); err != nil {
+		t.Fatalf("GetAccountSummary() error = %v", err)
+	}
+
+	if calls := node.calls("gettransaction"); len(calls) != 2 {
+		t.Errorf("GetAccountSummary() made %d gettransaction calls, want 2", len(calls))
+	}
+}
@@ -2,10 +2,12 @@ package bus
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/ledgerhq/satstack/types"
 	"github.com/ledgerhq/satstack/utils"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 )
 
@@ -22,9 +24,39 @@ func (b *Bus) GetBlockHash(height int64) (*chainhash.Hash, error) {
 	return b.mainClient.GetBlockHash(height)
 }
 
+// verboseBlock models the subset of the getblock response (verbosity 1)
+// needed to build a types.Block.
+//
+// The btcd library does not model the mediantime field, so we use a raw
+// request instead.
+type verboseBlock struct {
+	Hash       string   `json:"hash"`
+	Height     int64    `json:"height"`
+	Time       int64    `json:"time"`
+	MedianTime int64    `json:"mediantime"`
+	Tx         []string `json:"tx"`
+}
+
 func (b *Bus) GetBlock(hash *chainhash.Hash) (*types.Block, error) {
-	nativeBlock, err := b.mainClient.GetBlockVerbose(hash)
+	hashJSON, err := json.Marshal(hash.String())
+	if err != nil {
+		return nil, err
+	}
+
+	verbosityJSON, err := json.Marshal(1)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("getblock", []json.RawMessage{
+		hashJSON, verbosityJSON,
+	})
 	if err != nil {
+		return nil, b.checkBlockPruned(hash, err)
+	}
+
+	var nativeBlock verboseBlock
+	if err := json.Unmarshal(result, &nativeBlock); err != nil {
 		return nil, err
 	}
 
@@ -37,12 +69,281 @@ func (b *Bus) GetBlock(hash *chainhash.Hash) (*types.Block, error) {
 		Hash:         nativeBlock.Hash,
 		Height:       nativeBlock.Height,
 		Time:         utils.ParseUnixTimestamp(nativeBlock.Time),
+		MedianTime:   utils.ParseUnixTimestamp(nativeBlock.MedianTime),
 		Transactions: &transactions,
 	}
 
 	return &block, nil
 }
 
+// GetRawBlock returns the serialized block with the given hash, hex-encoded,
+// so that clients can parse and verify it themselves. This is cheaper for the
+// node than the decoded block.
+//
+// ErrBlockPruned is returned if the block is below the prune height.
+func (b *Bus) GetRawBlock(hash *chainhash.Hash) (string, error) {
+	hashJSON, err := json.Marshal(hash.String())
+	if err != nil {
+		return "", err
+	}
+
+	verbosityJSON, err := json.Marshal(0)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := b.mainClient.RawRequest("getblock", []json.RawMessage{
+		hashJSON, verbosityJSON,
+	})
+	if err != nil {
+		return "", b.checkBlockPruned(hash, err)
+	}
+
+	var blockHex string
+	if err := json.Unmarshal(result, &blockHex); err != nil {
+		return "", err
+	}
+
+	return blockHex, nil
+}
+
+// blockStatsResult models the subset of the getblockstats response needed to
+// build a types.BlockStats.
+type blockStatsResult struct {
+	Hash               string  `json:"blockhash"`
+	Height             int64   `json:"height"`
+	Time               int64   `json:"time"`
+	Txs                int64   `json:"txs"`
+	Ins                int64   `json:"ins"`
+	Outs               int64   `json:"outs"`
+	TotalFee           int64   `json:"totalfee"`
+	Subsidy            int64   `json:"subsidy"`
+	AvgFeeRate         int64   `json:"avgfeerate"`
+	FeeRatePercentiles []int64 `json:"feerate_percentiles"` // 10th, 25th, 50th, 75th and 90th
+	SegwitTxs          int64   `json:"swtxs"`
+}
+
+// GetBlockStats returns statistics of the block with the given hash, such as
+// its fees and the share of segwit transactions, without scanning its
+// transactions on the client side.
+//
+// ErrBlockPruned is returned if the block is below the prune height, since
+// the node needs the block data to compute the statistics.
+func (b *Bus) GetBlockStats(hash *chainhash.Hash) (*types.BlockStats, error) {
+	hashJSON, err := json.Marshal(hash.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("getblockstats", []json.RawMessage{hashJSON})
+	if err != nil {
+		return nil, b.checkBlockPruned(hash, err)
+	}
+
+	var stats blockStatsResult
+	if err := json.Unmarshal(result, &stats); err != nil {
+		return nil, err
+	}
+
+	var medianFeeRate int64
+	if len(stats.FeeRatePercentiles) == 5 {
+		medianFeeRate = stats.FeeRatePercentiles[2]
+	}
+
+	// The coinbase transaction is not counted in the segwit transactions.
+	var segwitShare float64
+	if stats.Txs > 1 {
+		segwitShare = float64(stats.SegwitTxs) / float64(stats.Txs-1)
+	}
+
+	return &types.BlockStats{
+		Hash:          stats.Hash,
+		Height:        stats.Height,
+		Time:          utils.ParseUnixTimestamp(stats.Time),
+		TxCount:       stats.Txs,
+		InputCount:    stats.Ins,
+		OutputCount:   stats.Outs,
+		TotalFee:      btcutil.Amount(stats.TotalFee),
+		Subsidy:       btcutil.Amount(stats.Subsidy),
+		AvgFeeRate:    stats.AvgFeeRate,
+		MedianFeeRate: medianFeeRate,
+		SegwitShare:   segwitShare,
+	}, nil
+}
+
+// verboseHeader models the subset of the getblockheader response (verbose)
+// needed to search blocks by time, and to summarize a block without fetching
+// its transactions.
+//
+// The btcd library does not model the mediantime and nTx fields, so we use a
+// raw request instead.
+type verboseHeader struct {
+	Hash       string `json:"hash"`
+	Height     int64  `json:"height"`
+	Time       int64  `json:"time"`
+	MedianTime int64  `json:"mediantime"`
+	TxCount    int64  `json:"nTx"`
+}
+
+func (b *Bus) getVerboseHeader(hash *chainhash.Hash) (*verboseHeader, error) {
+	hashJSON, err := json.Marshal(hash.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("getblockheader", []json.RawMessage{hashJSON})
+	if err != nil {
+		return nil, err
+	}
+
+	var header verboseHeader
+	if err := json.Unmarshal(result, &header); err != nil {
+		return nil, err
+	}
+
+	return &header, nil
+}
+
+func (b *Bus) getMedianTime(height int64) (int64, error) {
+	hash, err := b.mainClient.GetBlockHash(height)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
+	}
+
+	header, err := b.getVerboseHeader(hash)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
+	}
+
+	return header.MedianTime, nil
+}
+
+// GetBlockSummary returns a Block with the number of transactions, but
+// without the transaction IDs.
+//
+// Only the block header is fetched, which keeps this cheap enough for
+// clients polling the chain tip.
+func (b *Bus) GetBlockSummary(hash *chainhash.Hash) (*types.Block, error) {
+	header, err := b.getVerboseHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	txCount := header.TxCount
+
+	return &types.Block{
+		Hash:       header.Hash,
+		Height:     header.Height,
+		Time:       utils.ParseUnixTimestamp(header.Time),
+		MedianTime: utils.ParseUnixTimestamp(header.MedianTime),
+		TxCount:    &txCount,
+	}, nil
+}
+
+// GetBlockHeightByTime returns the height of the first block whose median
+// time past is at or after the given unix time.
+//
+// Unlike the block time, the median time past is monotonic, which makes a
+// binary search over block headers possible. Times before the genesis block
+// resolve to height 0, while times after the median time past of the tip
+// return ErrTimeAfterTip.
+func (b *Bus) GetBlockHeightByTime(unixTime int64) (int64, error) {
+	count, err := b.mainClient.GetBlockCount()
+	if err != nil {
+		return 0, err
+	}
+
+	tipTime, err := b.getMedianTime(count)
+	if err != nil {
+		return 0, err
+	}
+
+	if tipTime < unixTime {
+		return 0, fmt.Errorf("%w: %d > %d (tip %d)",
+			ErrTimeAfterTip, unixTime, tipTime, count)
+	}
+
+	return searchHeight(count, func(height int64) (bool, error) {
+		medianTime, err := b.getMedianTime(height)
+		return medianTime < unixTime, err
+	})
+}
+
+// searchHeight returns the first height in [0, count] for which before
+// reports false, assuming that it does so for all the following heights.
+//
+// Heights are visited with a binary search, and the first error returned by
+// before is returned as is.
+func searchHeight(count int64, before func(height int64) (bool, error)) (int64, error) {
+	low, high := int64(0), count
+	for low < high {
+		mid := low + (high-low)/2
+
+		isBefore, err := before(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if isBefore {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+// GetBlockFilter returns the BIP158 basic compact block filter of the block
+// with the given hash.
+//
+// The block filter index must be enabled on the node (blockfilterindex=1),
+// otherwise ErrBlockFilterDisabled is returned.
+func (b *Bus) GetBlockFilter(hash *chainhash.Hash) (*types.BlockFilter, error) {
+	if !b.BlockFilter {
+		return nil, ErrBlockFilterDisabled
+	}
+
+	result, err := b.mainClient.GetBlockFilter(*hash, nil)
+	if err != nil {
+		return nil, b.checkBlockPruned(hash, err)
+	}
+
+	return &types.BlockFilter{
+		Filter: result.Filter,
+		Header: result.Header,
+	}, nil
+}
+
+// checkBlockPruned inspects an error encountered while fetching a block, and
+// returns ErrBlockPruned if the block data is unavailable because it is below
+// the prune height of the node. Otherwise, the original error is returned.
+//
+// Block headers are never pruned, so they are used to tell the difference
+// between an unknown block and a pruned one.
+func (b *Bus) checkBlockPruned(hash *chainhash.Hash, err error) error {
+	if !b.Pruned {
+		return err
+	}
+
+	header, headerErr := b.mainClient.GetBlockHeaderVerbose(hash)
+	if headerErr != nil {
+		return err
+	}
+
+	info, infoErr := b.GetBlockChainInfo()
+	if infoErr != nil {
+		return err
+	}
+
+	if header.Height >= info.PruneHeight {
+		return err
+	}
+
+	return fmt.Errorf("%w: block %d is below pruneheight %d",
+		ErrBlockPruned, header.Height, info.PruneHeight)
+}
+
 func (b *Bus) GetBlockChainInfo() (*types.BlockChainInfo, error) {
 	// The `softforks` field is a map in the btcd library, but a slice in
 	// the Bitcoin Core RPC. This was fixed in btcd master, but the latest
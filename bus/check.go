@@ -0,0 +1,208 @@
+package bus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/ledgerhq/satstack/config"
+)
+
+// CheckResult is the outcome of a single check performed by Check.
+type CheckResult struct {
+	Name   string // Short description of what was checked
+	Detail string // Additional information on success, if any
+	Err    error  // Reason of the failure, nil if the check passed
+}
+
+// Check performs a series of read-only probes of the connected node against
+// the given configuration, without loading or creating the wallet, or
+// importing any descriptor.
+//
+// The checks stop at the first failure that makes subsequent ones
+// meaningless, for ex if bitcoind is unreachable.
+func Check(configuration *config.Configuration) []CheckResult {
+	var results []CheckResult
+
+	walletName := configuredWalletName(configuration)
+
+	connCfg, transport, err := newConnConfig(configuration, walletName)
+	if err != nil {
+		return append(results, CheckResult{Name: "RPC connection config", Err: err})
+	}
+
+	client, err := newRPCClient(connCfg, transport)
+	if err != nil {
+		return append(results, CheckResult{Name: "RPC connection config", Err: err})
+	}
+
+	defer client.Shutdown()
+
+	// Reachability and authentication
+	result, err := client.RawRequest("getblockchaininfo", nil)
+	switch {
+	case IsAuthError(err):
+		return append(results,
+			CheckResult{Name: "bitcoind reachable", Detail: *configuration.RPCURL},
+			CheckResult{Name: "RPC authentication", Err: err})
+	case isWarmingUp(err):
+		return append(results,
+			CheckResult{Name: "bitcoind reachable", Detail: *configuration.RPCURL},
+			CheckResult{Name: "RPC authentication"},
+			CheckResult{Name: "bitcoind ready", Err: err})
+	case err != nil:
+		return append(results, CheckResult{
+			Name: "bitcoind reachable",
+			Err:  fmt.Errorf("%s: %w", ErrBitcoindUnreachable, err),
+		})
+	}
+
+	results = append(results,
+		CheckResult{Name: "bitcoind reachable", Detail: *configuration.RPCURL},
+		CheckResult{Name: "RPC authentication"})
+
+	var info struct {
+		Chain         string `json:"chain"`
+		BestBlockHash string `json:"bestblockhash"`
+	}
+
+	if err := json.Unmarshal(result, &info); err != nil {
+		return append(results, CheckResult{
+			Name: "chain",
+			Err:  fmt.Errorf("unable to parse blockchain info: %w", err),
+		})
+	}
+
+	// Chain and currency
+	results = append(results, checkChain(configuration, info.Chain))
+
+	params, err := ChainParams(info.Chain, configuration.SignetChallenge)
+	if err != nil {
+		return results
+	}
+
+	// Indexes
+	txIndex, err := txIndexEnabled(client)
+	switch {
+	case err != nil:
+		results = append(results, CheckResult{
+			Name: "txindex",
+			Err:  fmt.Errorf("%s: %w", ErrFailedToDetectTxIndex, err),
+		})
+	case txIndex:
+		results = append(results, CheckResult{Name: "txindex", Detail: "enabled"})
+	default:
+		results = append(results, CheckResult{
+			Name:   "txindex",
+			Detail: "disabled, only wallet transactions can be queried",
+		})
+	}
+
+	// Descriptors
+	for idx, account := range configuration.Accounts {
+		name := fmt.Sprintf("account #%d descriptors", idx)
+
+		if account.XPub != nil {
+			external, internal, err := accountDescriptors(account, params)
+			if err != nil {
+				results = append(results, CheckResult{Name: name, Err: err})
+				continue
+			}
+
+			account.External = &external
+			account.Internal = &internal
+		}
+
+		results = append(results, checkDescriptors(client, name, account))
+	}
+
+	// Wallet
+	results = append(results, checkWallet(client, walletName, !configuration.NoCreateWallet))
+
+	return results
+}
+
+// checkChain verifies that the currency of the chain the node is connected
+// to matches the configured currency, if any.
+func checkChain(configuration *config.Configuration, chain string) CheckResult {
+	currency, err := CurrencyFromChain(chain)
+	if err != nil {
+		return CheckResult{Name: "chain", Err: err}
+	}
+
+	if expected := configuration.Currency; expected != "" && expected != currency {
+		return CheckResult{
+			Name: "chain",
+			Err: fmt.Errorf("%s: node is on chain '%s' (%s), but config expects %s",
+				ErrChainMismatch, chain, currency, expected),
+		}
+	}
+
+	return CheckResult{Name: "chain", Detail: fmt.Sprintf("%s (%s)", chain, currency)}
+}
+
+// checkDescriptors verifies that the node accepts the external and internal
+// descriptors of the account, including their checksums if present.
+func checkDescriptors(client *rpcclient.Client, name string, account config.Account) CheckResult {
+	for _, desc := range []*string{account.External, account.Internal} {
+		if desc == nil {
+			continue
+		}
+
+		if err := VerifyDescriptorChecksum(client, *desc); err != nil {
+			return CheckResult{Name: name, Err: err}
+		}
+
+		if _, err := client.GetDescriptorInfo(*desc); err != nil {
+			return CheckResult{
+				Name: name,
+				Err:  fmt.Errorf("%s: %w", ErrInvalidDescriptor, err),
+			}
+		}
+	}
+
+	return CheckResult{Name: name}
+}
+
+// checkWallet verifies that the wallet exists on the node, or that SatStack
+// is allowed to create it.
+func checkWallet(client *rpcclient.Client, walletName string, create bool) CheckResult {
+	name := fmt.Sprintf("wallet '%s'", walletName)
+
+	result, err := client.RawRequest("listwalletdir", nil)
+	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCMethodNotFound.Code {
+			return CheckResult{Name: name, Err: ErrWalletDisabled}
+		}
+
+		return CheckResult{Name: name, Err: fmt.Errorf("%s: %w", ErrLoadWallet, err)}
+	}
+
+	var walletDir struct {
+		Wallets []struct {
+			Name string `json:"name"`
+		} `json:"wallets"`
+	}
+
+	if err := json.Unmarshal(result, &walletDir); err != nil {
+		return CheckResult{Name: name, Err: fmt.Errorf("%s: %w", ErrLoadWallet, err)}
+	}
+
+	for _, wallet := range walletDir.Wallets {
+		if wallet.Name == walletName {
+			return CheckResult{Name: name, Detail: "exists"}
+		}
+	}
+
+	if !create {
+		return CheckResult{
+			Name: name,
+			Err:  fmt.Errorf("%s: %s", ErrWalletNotFound, walletName),
+		}
+	}
+
+	return CheckResult{Name: name, Detail: "will be created on startup"}
+}
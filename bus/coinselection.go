@@ -0,0 +1,136 @@
+package bus
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ledgerhq/satstack/types"
+)
+
+// txOverheadVSize is the virtual size of the fields of a segwit transaction
+// that do not depend on its inputs and outputs (version, locktime, counts,
+// and witness marker), rounded up.
+const txOverheadVSize = 11
+
+// defaultDustLimit is the smallest change amount worth creating an output
+// for. Smaller amounts are added to the fee instead.
+const defaultDustLimit btcutil.Amount = 546
+
+// scriptVSize models the virtual size of an input spending a script, and of
+// an output paying to it.
+type scriptVSize struct {
+	Input  int64
+	Output int64
+}
+
+// scriptVSizes lists the sizes of the script types that can be selected,
+// assuming single-key scripts. P2SH outputs are assumed to wrap P2WPKH, as
+// used by BIP49 accounts.
+var scriptVSizes = map[txscript.ScriptClass]scriptVSize{
+	txscript.PubKeyHashTy:          {Input: 148, Output: 34},
+	txscript.ScriptHashTy:          {Input: 91, Output: 32},
+	txscript.WitnessV0PubKeyHashTy: {Input: 68, Output: 31},
+	txscript.WitnessV1TaprootTy:    {Input: 58, Output: 43},
+}
+
+// SelectCoins picks UTXOs of the account made of the given descriptors to
+// pay the target amount at the given fee rate (in sat/vB), without signing
+// anything. UTXOs are selected largest-first, among those with at least
+// minConf confirmations. Immature coinbase outputs are never selected, since
+// bitcoind does not list them as spendable.
+//
+// The fee assumes that the recipient and change outputs are of the same
+// script type as the largest selected UTXO. If the change would be below the
+// dust limit, it is dropped and added to the fee.
+//
+// An empty descriptor list, or descriptors deriving no addresses, are rejected
+// with ErrInvalidDescriptor rather than selecting across the whole wallet.
+func (b *Bus) SelectCoins(descriptors []string, target btcutil.Amount, feeRate float64, minConf int) (*types.CoinSelection, error) {
+	if target <= 0 || feeRate <= 0 {
+		return nil, fmt.Errorf("%w: target %d, fee rate %v", ErrInvalidCoinSelection, target, feeRate)
+	}
+
+	addresses, err := b.importedAddresses(descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	utxos, err := b.ListUnspent(addresses, minConf)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(utxos, func(i, j int) bool {
+		return utxos[i].Value > utxos[j].Value
+	})
+
+	fee := func(vsize int64) btcutil.Amount {
+		return btcutil.Amount(math.Ceil(feeRate * float64(vsize)))
+	}
+
+	var (
+		selected   []types.UnspentOutput
+		total      btcutil.Amount
+		inputVSize int64
+		outputSize int64
+	)
+
+	for _, utxo := range utxos {
+		sizes, ok := utxoVSize(utxo)
+		if !ok {
+			continue // script type whose spending size cannot be estimated
+		}
+
+		if selected == nil {
+			outputSize = sizes.Output
+		}
+
+		selected = append(selected, utxo)
+		total += utxo.Value
+		inputVSize += sizes.Input
+
+		// Pay the target with a change output, if it is worth creating.
+		vsize := txOverheadVSize + inputVSize + 2*outputSize
+		if change := total - target - fee(vsize); change >= defaultDustLimit {
+			return &types.CoinSelection{
+				Inputs:  selected,
+				Target:  target,
+				Fee:     fee(vsize),
+				Change:  change,
+				FeeRate: feeRate,
+				VSize:   vsize,
+			}, nil
+		}
+
+		// Otherwise, pay the target without change, and leave the excess to
+		// the miners.
+		vsize = txOverheadVSize + inputVSize + outputSize
+		if total >= target+fee(vsize) {
+			return &types.CoinSelection{
+				Inputs:  selected,
+				Target:  target,
+				Fee:     total - target,
+				FeeRate: feeRate,
+				VSize:   vsize,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %d available for a target of %d",
+		ErrInsufficientFunds, total, target)
+}
+
+// utxoVSize returns the sizes of the script type of a UTXO, if supported.
+func utxoVSize(utxo types.UnspentOutput) (scriptVSize, bool) {
+	script, err := hex.DecodeString(utxo.ScriptHex)
+	if err != nil {
+		return scriptVSize{}, false
+	}
+
+	sizes, ok := scriptVSizes[txscript.GetScriptClass(script)]
+	return sizes, ok
+}
@@ -0,0 +1,207 @@
+package bus
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+func TestSelectCoins(t *testing.T) {
+	const desc = "wpkh([8a2b9c1d/84'/1'/0']tpubA/0/*)"
+
+	params := &chaincfg.RegressionNetParams
+
+	address, err := btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With P2WPKH inputs and outputs, a transaction spending n inputs is
+	// 11+68n vbytes, plus 31 vbytes per output.
+	tests := []struct {
+		name    string
+		utxos   []btcutil.Amount
+		script  string // scriptPubKey of the largest UTXO, if not P2WPKH
+		target  btcutil.Amount
+		feeRate float64
+		inputs  []btcutil.Amount
+		fee     btcutil.Amount
+		change  btcutil.Amount
+		vsize   int64
+		err     error
+	}{
+		{
+			name:    "largest first",
+			utxos:   []btcutil.Amount{10000, 50000, 20000},
+			target:  30000,
+			feeRate: 1,
+			inputs:  []btcutil.Amount{50000},
+			fee:     141,
+			change:  19859,
+			vsize:   141,
+		},
+		{
+			name:    "fee rate",
+			utxos:   []btcutil.Amount{10000, 50000, 20000},
+			target:  30000,
+			feeRate: 2.5,
+			inputs:  []btcutil.Amount{50000},
+			fee:     353,
+			change:  19647,
+			vsize:   141,
+		},
+		{
+			name:    "several inputs",
+			utxos:   []btcutil.Amount{5000, 15000, 20000},
+			target:  30000,
+			feeRate: 1,
+			inputs:  []btcutil.Amount{20000, 15000},
+			fee:     209,
+			change:  4791,
+			vsize:   209,
+		},
+		{
+			name:    "change at the dust limit",
+			utxos:   []btcutil.Amount{30687},
+			target:  30000,
+			feeRate: 1,
+			inputs:  []btcutil.Amount{30687},
+			fee:     141,
+			change:  546,
+			vsize:   141,
+		},
+		{
+			name:    "change below the dust limit",
+			utxos:   []btcutil.Amount{30686},
+			target:  30000,
+			feeRate: 1,
+			inputs:  []btcutil.Amount{30686},
+			fee:     686,
+			vsize:   110,
+		},
+		{
+			name:    "exact amount",
+			utxos:   []btcutil.Amount{30110},
+			target:  30000,
+			feeRate: 1,
+			inputs:  []btcutil.Amount{30110},
+			fee:     110,
+			vsize:   110,
+		},
+		{
+			name:    "unsupported script",
+			utxos:   []btcutil.Amount{10000, 90000, 50000},
+			script:  "6a",
+			target:  30000,
+			feeRate: 1,
+			inputs:  []btcutil.Amount{50000},
+			fee:     141,
+			change:  19859,
+			vsize:   141,
+		},
+		{
+			name:    "insufficient funds",
+			utxos:   []btcutil.Amount{10000, 20000},
+			target:  30000,
+			feeRate: 1,
+			err:     ErrInsufficientFunds,
+		},
+		{
+			name:    "insufficient funds for the fee",
+			utxos:   []btcutil.Amount{30109},
+			target:  30000,
+			feeRate: 1,
+			err:     ErrInsufficientFunds,
+		},
+		{
+			name:    "no fee rate",
+			utxos:   []btcutil.Amount{50000},
+			target:  30000,
+			feeRate: 0,
+			err:     ErrInvalidCoinSelection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var largest btcutil.Amount
+			for _, value := range tt.utxos {
+				if value > largest {
+					largest = value
+				}
+			}
+
+			unspents := make([]string, 0, len(tt.utxos))
+			for i, value := range tt.utxos {
+				script := hex.EncodeToString(pkScript)
+				if value == largest && tt.script != "" {
+					script = tt.script
+				}
+
+				unspents = append(unspents, fmt.Sprintf(
+					`{"txid":"%064x","vout":0,"address":"%s","scriptPubKey":"%s","amount":%s,"confirmations":1,"spendable":true}`,
+					i+1, address, script, strings.TrimSuffix(value.Format(btcutil.AmountBTC), " BTC")))
+			}
+
+			node, client := newRecordingNode(t, map[string]string{
+				"getdescriptorinfo": `{"descriptor":"` + desc + `#aaaaaaaa","checksum":"aaaaaaaa","isrange":true}`,
+				"listdescriptors":   `{"wallet_name":"satstack","descriptors":[{"desc":"` + desc + `#aaaaaaaa","range":[0,0]}]}`,
+				"deriveaddresses":   `["` + address.String() + `"]`,
+				"listunspent":       `[` + strings.Join(unspents, ",") + `]`,
+			})
+
+			b := &Bus{mainClient: client, Params: params}
+
+			selection, err := b.SelectCoins([]string{desc}, tt.target, tt.feeRate, 1)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("SelectCoins() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SelectCoins() error = %v", err)
+			}
+
+			if calls := node.calls("listunspent"); len(calls) != 1 {
+				t.Errorf("SelectCoins() made %d listunspent calls, want 1", len(calls))
+			}
+
+			var inputs []btcutil.Amount
+			for _, input := range selection.Inputs {
+				inputs = append(inputs, input.Value)
+			}
+
+			if fmt.Sprint(inputs) != fmt.Sprint(tt.inputs) {
+				t.Errorf("SelectCoins() inputs = %v, want %v", inputs, tt.inputs)
+			}
+
+			if selection.Fee != tt.fee || selection.Change != tt.change || selection.VSize != tt.vsize {
+				t.Errorf("SelectCoins() fee = %d, change = %d, vsize = %d, want %d, %d, %d",
+					selection.Fee, selection.Change, selection.VSize, tt.fee, tt.change, tt.vsize)
+			}
+
+			// The inputs pay for the target, the fee and the change.
+			var total btcutil.Amount
+			for _, value := range inputs {
+				total += value
+			}
+
+			if total != tt.target+selection.Fee+selection.Change {
+				t.Errorf("SelectCoins() inputs total %d, want %d", total, tt.target+selection.Fee+selection.Change)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package bus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ledgerhq/satstack/config"
+	"github.com/ledgerhq/satstack/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// hardenedMarkerRegexp matches the "h" notation for hardened derivation
+// steps, which Bitcoin Core may use in place of an apostrophe.
+var hardenedMarkerRegexp = regexp.MustCompile(`/(\d+)[hH]`)
+
+// GetDescriptorInfo returns how the node interprets the given descriptor.
+func (b *Bus) GetDescriptorInfo(descriptor string) (*types.DescriptorInfo, error) {
+	info, err := b.mainClient.GetDescriptorInfo(descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+	}
+
+	return &types.DescriptorInfo{
+		Descriptor:     info.Descriptor,
+		Checksum:       info.Checksum,
+		IsRange:        info.IsRange,
+		IsSolvable:     info.IsSolvable,
+		HasPrivateKeys: info.HasPrivateKeys,
+	}, nil
+}
+
+// maxDerivationIndex is the largest non-hardened BIP32 child index.
+const maxDerivationIndex = 1<<31 - 1
+
+// DeriveAddressAt returns the address at the given index of a ranged
+// descriptor, for ex to check that a receive address matches the one shown by
+// the device.
+func (b *Bus) DeriveAddressAt(descriptor string, index int) (string, error) {
+	if index < 0 || index > maxDerivationIndex {
+		return "", fmt.Errorf("%w: %d", ErrIndexOutOfRange, index)
+	}
+
+	info, err := b.mainClient.GetDescriptorInfo(descriptor)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+	}
+
+	if !info.IsRange {
+		return "", fmt.Errorf("%w: %s", ErrDescriptorNotRanged, descriptor)
+	}
+
+	address, err := DeriveAddress(b.mainClient, info.Descriptor, index)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ErrDeriveAddress, err)
+	}
+
+	return *address, nil
+}
+
+// DescriptorDrift lists the differences between the descriptors imported in
+// the Bitcoin Core wallet, and those computed from the SatStack config.
+type DescriptorDrift struct {
+	// Orphaned descriptors are present in the wallet, but no longer
+	// correspond to any configured account.
+	Orphaned []string `json:"orphaned,omitempty"`
+
+	// Missing descriptors are configured, but not imported in the wallet.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// IsEmpty reports whether the wallet and the config are in sync.
+func (d *DescriptorDrift) IsEmpty() bool {
+	return len(d.Orphaned) == 0 && len(d.Missing) == 0
+}
+
+// listDescriptorsResult models the result of the listdescriptors RPC.
+type listDescriptorsResult struct {
+	WalletName  string `json:"wallet_name"`
+	Descriptors []struct {
+		Descriptor string `json:"desc"`
+		Range      []int  `json:"range,omitempty"` // [begin, end] of ranged descriptors
+		Timestamp  uint32 `json:"timestamp"`       // UNIX timestamp from which the descriptor was scanned
+	} `json:"descriptors"`
+}
+
+// normalizeDescriptor strips the checksum out of a descriptor, and rewrites
+// hardened derivation steps with an apostrophe, so that descriptors returned
+// by different RPCs can be compared.
+func normalizeDescriptor(descriptor string) string {
+	descriptor = strings.Split(descriptor, "#")[0]
+	return hardenedMarkerRegexp.ReplaceAllString(descriptor, "/$1'")
+}
+
+// ExportDescriptors returns the descriptors watched by the wallet, with their
+// checksum but without private keys, so that they can be backed up or
+// imported in another wallet.
+//
+// Unlike the config, the result lists the descriptors generated from xpubs,
+// and exactly what is watched.
+func (b *Bus) ExportDescriptors() ([]string, error) {
+	if b.WalletFormat == LegacyWallet {
+		return nil, ErrDescriptorWalletRequired
+	}
+
+	imported, err := b.listDescriptors()
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := make([]string, 0, len(imported.Descriptors))
+	for _, desc := range imported.Descriptors {
+		descriptors = append(descriptors, desc.Descriptor)
+	}
+
+	return descriptors, nil
+}
+
+// RescanNeeded returns the canonical descriptors of the configured accounts
+// that the wallet may not have scanned completely, and that need a rescan
+// from the birthday of their account. A descriptor has a gap if:
+//   - it is not imported in the wallet,
+//   - it was imported with a timestamp later than the birthday, so that the
+//     blocks in between were never scanned, or
+//   - it is ranged, and was imported with fewer addresses than the depth.
+//
+// Birthdays of "now" resolve to the time of the chain tip, so that their
+// descriptors never have a timestamp gap. Descriptors imported for a deferred
+// rescan keep the time of the import as timestamp, so their timestamp gap is
+// only considered covered if the rescan checkpoint reaches the chain tip, the
+// rescanned range starts at or before the birthday, and its rescan began
+// after the import.
+//
+// Legacy wallets cannot list their descriptors, and are rejected with
+// ErrDescriptorWalletRequired.
+func (b *Bus) RescanNeeded() ([]string, error) {
+	if b.WalletFormat == LegacyWallet {
+		return nil, ErrDescriptorWalletRequired
+	}
+
+	accounts, err := b.expandAccounts(b.Accounts())
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := b.ClientFactory()
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.Shutdown()
+
+	imported, err := b.listDescriptors()
+	if err != nil {
+		return nil, err
+	}
+
+	importedDescs := make(map[string]int, len(imported.Descriptors))
+	for i, desc := range imported.Descriptors {
+		importedDescs[normalizeDescriptor(desc.Descriptor)] = i
+	}
+
+	tip, err := client.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+
+	covered := rescanCoverage(tip)
+
+	// Heights of the birthdays, which are shared by the descriptors of an
+	// account.
+	birthdayHeights := make(map[uint32]int64)
+
+	var gaps []string
+	for _, account := range accounts {
+		accountDescriptors, err := descriptors(client, account)
+		if err != nil {
+			return nil, err // return bare error, since it already has a ctx
+		}
+
+		for _, desc := range accountDescriptors {
+			i, ok := importedDescs[normalizeDescriptor(desc.Value)]
+			if !ok {
+				gaps = append(gaps, desc.Value)
+				continue
+			}
+
+			importedDesc := imported.Descriptors[i]
+			rangeGap := len(importedDesc.Range) == 2 && importedDesc.Range[1] < desc.Depth
+			timestampGap := importedDesc.Timestamp > desc.Age
+
+			// Descriptors imported after the rescan began were not rescanned.
+			if timestampGap && covered != nil && int64(importedDesc.Timestamp) <= covered.Time {
+				birthdayHeight, ok := birthdayHeights[desc.Age]
+				if !ok {
+					birthdayHeight, err = heightAtTime(client, desc.Age)
+					if err != nil {
+						return nil, err
+					}
+
+					birthdayHeights[desc.Age] = birthdayHeight
+				}
+
+				timestampGap = birthdayHeight < covered.Start
+			}
+
+			if timestampGap || rangeGap {
+				log.WithFields(log.Fields{
+					"descriptor":        desc.Value,
+					"importedTimestamp": importedDesc.Timestamp,
+					"birthday":          desc.Age,
+					"importedRange":     importedDesc.Range,
+					"depth":             desc.Depth,
+				}).Debug("Descriptor needs a rescan")
+
+				gaps = append(gaps, desc.Value)
+			}
+		}
+	}
+
+	return gaps, nil
+}
+
+// rescannedRange is a range of blocks rescanned up to the chain tip, for the
+// descriptors imported before its rescan began.
+type rescannedRange struct {
+	Start int64 // First block of the range
+	Time  int64 // Median time past of the tip when the rescan began
+}
+
+// rescanCoverage returns the range rescanned up to the chain tip, according
+// to the rescan checkpoint. It returns nil if the checkpoint does not reach
+// the tip, or does not record where and when a completed rescan started.
+func rescanCoverage(tip int64) *rescannedRange {
+	checkpoint, err := config.LoadRescanConf()
+	if err != nil || checkpoint.RescanStart == nil || checkpoint.RescanTime == nil ||
+		checkpoint.LastBlock < tip {
+		return nil
+	}
+
+	return &rescannedRange{Start: *checkpoint.RescanStart, Time: *checkpoint.RescanTime}
+}
+
+// DescriptorDrift returns the result of the last descriptor reconciliation,
+// or nil if none completed yet.
+func (b *Bus) DescriptorDrift() *DescriptorDrift {
+	return b.descriptorDrift.Load()
+}
+
+// CheckDescriptorDrift compares the descriptors imported in the wallet with
+// the canonical descriptors of the given accounts. The result is stored in
+// Bus.DescriptorDrift, and a warning is logged if they are out of sync.
+func (b *Bus) CheckDescriptorDrift(accounts []config.Account) (*DescriptorDrift, error) {
+	accounts, err := b.expandAccounts(accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := b.ClientFactory()
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.Shutdown()
+
+	expected := make(map[string]string)
+	for _, account := range accounts {
+		for _, desc := range []string{*account.External, *account.Internal} {
+			canonicalDesc, err := GetCanonicalDescriptor(client, strings.Split(desc, "#")[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ErrInvalidDescriptor, err)
+			}
+
+			expected[normalizeDescriptor(*canonicalDesc)] = *canonicalDesc
+		}
+	}
+
+	imported, err := b.listDescriptors()
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &DescriptorDrift{}
+
+	seen := make(map[string]bool)
+	for _, desc := range imported.Descriptors {
+		normalized := normalizeDescriptor(desc.Descriptor)
+		seen[normalized] = true
+
+		if _, ok := expected[normalized]; !ok {
+			drift.Orphaned = append(drift.Orphaned, desc.Descriptor)
+		}
+	}
+
+	for normalized, desc := range expected {
+		if !seen[normalized] {
+			drift.Missing = append(drift.Missing, desc)
+		}
+	}
+
+	b.descriptorDrift.Store(drift)
+
+	if !drift.IsEmpty() {
+		log.WithFields(log.Fields{
+			"prefix":   "worker",
+			"wallet":   imported.WalletName,
+			"orphaned": drift.Orphaned,
+			"missing":  drift.Missing,
+		}).Warn("Wallet descriptors do not match the config; balances may be inconsistent")
+	}
+
+	return drift, nil
+}
@@ -0,0 +1,188 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ledgerhq/satstack/config"
+	"github.com/mitchellh/go-homedir"
+)
+
+// setRescanCheckpoint points the home directory to a temporary one, holding
+// the given lss_rescan.json contents, if any.
+func setRescanCheckpoint(t *testing.T, contents string) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+
+	if contents == "" {
+		return
+	}
+
+	dir := filepath.Join(home, ".satstack")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "lss_rescan.json"), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRescanNeededLegacyWallet(t *testing.T) {
+	b := &Bus{WalletFormat: LegacyWallet}
+
+	if _, err := b.RescanNeeded(); !errors.Is(err, ErrDescriptorWalletRequired) {
+		t.Fatalf("RescanNeeded() error = %v, want %v", err, ErrDescriptorWalletRequired)
+	}
+}
+
+// checkpoint returns the contents of a rescan checkpoint, of a range of
+// blocks rescanned from rescanStart, by a rescan which began at rescanTime.
+func checkpoint(lastBlock int64, rescanStart int64, rescanTime int64) string {
+	return fmt.Sprintf(`{"timestamp":"1700000000","last_block":%d,"rescan_start":%d,"rescan_time":%d}`,
+		lastBlock, rescanStart, rescanTime)
+}
+
+func TestRescanNeededDeferredRescan(t *testing.T) {
+	const desc = "wpkh([8a2b9c1d/84'/1'/0']tpubA/0/*)"
+
+	// The account has the default birthday, while its descriptor was
+	// imported later without a rescan, as done by --deferred-rescan. All the
+	// block headers are more recent than the birthday, which is therefore at
+	// height 0.
+	results := map[string]string{
+		"getdescriptorinfo": `{"descriptor":"` + desc + `#aaaaaaaa","checksum":"aaaaaaaa","isrange":true}`,
+		"listdescriptors": `{"wallet_name":"satstack","descriptors":[` +
+			`{"desc":"` + desc + `#aaaaaaaa","range":[0,1000],"timestamp":1700000000}]}`,
+		"getblockcount": `100`,
+		"getblockhash":  `"0000000000000000000000000000000000000000000000000000000000000001"`,
+		"getblockheader": `{"hash":"0000000000000000000000000000000000000000000000000000000000000001",` +
+			`"height":0,"time":1700000000}`,
+	}
+
+	tests := []struct {
+		name       string
+		checkpoint string
+		gap        bool
+	}{
+		{name: "never rescanned", gap: true},
+		{name: "rescanned to the tip from the birthday", checkpoint: checkpoint(100, 0, 1700000000)},
+		{name: "rescanned beyond the tip", checkpoint: checkpoint(101, 0, 1700000000)},
+		{name: "rescanned below the tip", checkpoint: checkpoint(99, 0, 1700000000), gap: true},
+		{name: "rescanned after the birthday", checkpoint: checkpoint(100, 5, 1700000000), gap: true},
+		{name: "rescan began before the import", checkpoint: checkpoint(100, 0, 1699999999), gap: true},
+		{name: "rescan not completed", checkpoint: `{"timestamp":"1700000000","last_block":100,"rescan_start":0}`, gap: true},
+		{name: "unknown rescan start", checkpoint: `{"timestamp":"1700000000","last_block":100}`, gap: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRescanCheckpoint(t, tt.checkpoint)
+
+			node, client := newRecordingNode(t, results)
+
+			b := &Bus{mainClient: client, connCfg: node.connCfg, WalletFormat: DescriptorWallet}
+
+			external, internal := desc, desc
+			b.SetAccounts([]config.Account{{External: &external, Internal: &internal}})
+
+			gaps, err := b.RescanNeeded()
+			if err != nil {
+				t.Fatalf("RescanNeeded() error = %v", err)
+			}
+
+			if gap := len(gaps) > 0; gap != tt.gap {
+				t.Errorf("RescanNeeded() = %v, want gap %v", gaps, tt.gap)
+			}
+		})
+	}
+}
+
+func TestRescanNeededAfterResumedRescan(t *testing.T) {
+	const desc = "wpkh([8a2b9c1d/84'/1'/0']tpubA/0/*)"
+
+	tests := []struct {
+		name      string
+		timestamp string
+		aborted   bool
+		gap       bool
+	}{
+		// The descriptor was tracked since the previous rescan, which the
+		// resumed one extends up to the tip.
+		{name: "imported before the previous rescan", timestamp: "1699998000"},
+		// The descriptor was imported by --deferred-rescan after the previous
+		// rescan, so it still misses the blocks rescanned before.
+		{name: "imported after the previous rescan", timestamp: "1700000000", gap: true},
+		// The range of the aborted rescan covers no descriptor, even once the
+		// checkpoint follows the tip on shutdown.
+		{name: "imported after the previous rescan, aborted", timestamp: "1700000000", aborted: true, gap: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The previous rescan completed up to height 49, from the birthday.
+			setRescanCheckpoint(t, checkpoint(49, 0, 1699999000))
+
+			node := &recordingNode{
+				results: map[string]string{
+					"getdescriptorinfo": `{"descriptor":"` + desc + `#aaaaaaaa","checksum":"aaaaaaaa","isrange":true}`,
+					"listdescriptors": `{"wallet_name":"satstack","descriptors":[` +
+						`{"desc":"` + desc + `#aaaaaaaa","range":[0,1000],"timestamp":` + tt.timestamp + `}]}`,
+					"getblockcount": `100`,
+					"getblockhash":  `"0000000000000000000000000000000000000000000000000000000000000001"`,
+					"getblockheader": `{"hash":"0000000000000000000000000000000000000000000000000000000000000001",` +
+						`"height":0,"time":1700000000,"mediantime":1700000500}`,
+					"rescanblockchain": `{"start_height":50,"stop_height":59}`,
+				},
+				errors: map[string]string{},
+			}
+
+			b := &Bus{WalletFormat: DescriptorWallet, rescanChunkSize: 10}
+
+			// AbortRescan is called while the first window is rescanned.
+			node.connCfg = serveStubNode(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				node.serveHTTP(w, r)
+
+				if tt.aborted && len(node.calls("rescanblockchain")) == 1 {
+					b.rescanAborted.Store(true)
+				}
+			}), false)
+
+			client, err := newRPCClient(node.connCfg, transportOptions{})
+			if err != nil {
+				t.Fatalf("newRPCClient() error = %v", err)
+			}
+			t.Cleanup(client.Shutdown)
+
+			b.mainClient, b.connCfg = client, node.connCfg
+
+			external, internal := desc, desc
+			b.SetAccounts([]config.Account{{External: &external, Internal: &internal}})
+
+			err = b.rescanWallet(50, 100)
+			if tt.aborted != errors.Is(err, ErrRescanAborted) || (!tt.aborted && err != nil) {
+				t.Fatalf("rescanWallet() error = %v, want aborted %v", err, tt.aborted)
+			}
+
+			if err := b.DumpLatestRescanTime(); err != nil {
+				t.Fatalf("DumpLatestRescanTime() error = %v", err)
+			}
+
+			gaps, err := b.RescanNeeded()
+			if err != nil {
+				t.Fatalf("RescanNeeded() error = %v", err)
+			}
+
+			if gap := len(gaps) > 0; gap != tt.gap {
+				t.Errorf("RescanNeeded() = %v, want gap %v", gaps, tt.gap)
+			}
+		})
+	}
+}
@@ -19,6 +19,10 @@ var (
 	// successful.
 	ErrLoadWallet = errors.New("failed to load wallet")
 
+	// ErrWalletNotFound indicates that the SatStack wallet does not exist on
+	// the connected Bitcoin node, and wallet creation is disabled.
+	ErrWalletNotFound = errors.New("wallet not found")
+
 	// ErrUnsupportedBitcoindVersion indicates that the connected bitcoind node
 	// has a version that is not supported by SatStack.
 	ErrUnsupportedBitcoindVersion = errors.New("unsupported bitcoind version")
@@ -30,6 +34,10 @@ var (
 	// network that libcore can understand.
 	ErrUnrecognizedChain = errors.New("unrecognized chain")
 
+	// ErrChainMismatch indicates that the chain of the connected bitcoind node
+	// does not correspond to the Currency configured in SatStack.
+	ErrChainMismatch = errors.New("chain mismatch")
+
 	// ErrFailedToGetBlock indicates that an error was encountered while
 	// trying to get a block.
 	ErrFailedToGetBlock = errors.New("failed to get block")
@@ -52,6 +60,10 @@ var (
 	// encountered.
 	ErrInvalidDescriptor = errors.New("invalid descriptor")
 
+	// ErrDescriptorChecksum indicates that the checksum of a descriptor does
+	// not match its contents.
+	ErrDescriptorChecksum = errors.New("descriptor checksum mismatch")
+
 	// ErrDeriveAddress indicates that an address could not be derived from a
 	// descriptor.
 	ErrDeriveAddress = errors.New("failed to derive address")
@@ -59,4 +71,165 @@ var (
 	// ErrAddressInfo indicates that an error was encountered while trying to
 	// fetch address info.
 	ErrAddressInfo = errors.New("failed to get address info")
+
+	// ErrSupplyNotComputed indicates that the circulating supply has not been
+	// computed yet. This is the case when the circulation check is disabled,
+	// or still in progress.
+	ErrSupplyNotComputed = errors.New("circulating supply not computed")
+
+	// ErrTxOutSetInfoAtHeight indicates that the UTXO set could not be
+	// queried at a specific height. This is the case on bitcoind versions
+	// lacking height-based gettxoutsetinfo queries, or when the
+	// coinstatsindex is disabled.
+	ErrTxOutSetInfoAtHeight = errors.New("failed to get txoutset info at height")
+
+	// ErrBlockPruned indicates that a block is known to the node, but its
+	// data is unavailable because it is below the prune height of a pruned
+	// node.
+	ErrBlockPruned = errors.New("block pruned")
+
+	// ErrInvalidAddress indicates that an address could not be decoded for
+	// the network of the connected node.
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrInvalidFeeMode indicates that a fee estimation mode is not one of
+	// the modes supported by estimatesmartfee.
+	ErrInvalidFeeMode = errors.New("invalid fee estimation mode")
+
+	// ErrInvalidFeeUnit indicates that a fee unit is not supported.
+	ErrInvalidFeeUnit = errors.New("invalid fee unit")
+
+	// ErrFeeEstimationFailed indicates that the node has not enough data to
+	// estimate the fee rate for a confirmation target.
+	ErrFeeEstimationFailed = errors.New("fee estimation failed")
+
+	// ErrTooLongMempoolChain indicates that a transaction was rejected by the
+	// node because it exceeds the mempool ancestor or descendant limits.
+	ErrTooLongMempoolChain = errors.New("too-long-mempool-chain")
+
+	// ErrNotInMempool indicates that a transaction is not in the mempool of
+	// the node. It may be confirmed, or unknown.
+	ErrNotInMempool = errors.New("transaction not in mempool")
+
+	// ErrNotReplaceable indicates that an unconfirmed transaction does not
+	// signal replaceability, as defined by BIP125.
+	ErrNotReplaceable = errors.New("transaction not replaceable")
+
+	// ErrTransactionConfirmed indicates that an operation is only valid on
+	// unconfirmed transactions, but the transaction is already confirmed.
+	ErrTransactionConfirmed = errors.New("transaction already confirmed")
+
+	// ErrInvalidHeight indicates that a block height is out of range.
+	ErrInvalidHeight = errors.New("invalid block height")
+
+	// ErrUnsupportedTransport indicates that the HTTP transport of the RPC
+	// client could not be customized, for ex to dial a Unix domain socket.
+	ErrUnsupportedTransport = errors.New("unsupported RPC client transport")
+
+	// ErrInvalidCACert indicates that the CA certificate configured to verify
+	// the RPC server could not be loaded.
+	ErrInvalidCACert = errors.New("invalid RPC CA certificate")
+
+	// ErrRescanAborted indicates that a chunked wallet rescan was stopped
+	// before covering its whole block range.
+	ErrRescanAborted = errors.New("rescan aborted")
+
+	// ErrNeverRescanned indicates that no rescan checkpoint exists, because
+	// the wallet was never successfully rescanned.
+	ErrNeverRescanned = errors.New("wallet never rescanned")
+
+	// ErrInvalidXPub indicates that the extended public key of an account
+	// could not be decoded, or does not match the network of the node.
+	ErrInvalidXPub = errors.New("invalid extended public key")
+
+	// ErrTimeAfterTip indicates that a timestamp is more recent than the
+	// median time past of the chain tip, so no block can be found at or
+	// after it yet.
+	ErrTimeAfterTip = errors.New("time is after the chain tip")
+
+	// ErrBlockFilterDisabled indicates that compact block filters were
+	// requested, but the block filter index of the node is not enabled.
+	ErrBlockFilterDisabled = errors.New("block filter index disabled, set blockfilterindex=1 in bitcoin.conf and restart bitcoind")
+
+	// ErrTxUnconfirmed indicates that an operation is only valid on confirmed
+	// transactions, for ex building a merkle proof.
+	ErrTxUnconfirmed = errors.New("transaction not confirmed")
+
+	// ErrScanInProgress indicates that a scan of the UTXO set could not be
+	// started, because another one is already running on the node.
+	ErrScanInProgress = errors.New("UTXO set scan already in progress")
+
+	// ErrDescriptorNotImported indicates that an operation scoped to an
+	// account requires its descriptors to be imported in the wallet.
+	ErrDescriptorNotImported = errors.New("descriptor not imported in the wallet")
+
+	// ErrInvalidCoinSelection indicates that the target amount or the fee
+	// rate of a coin selection is not positive.
+	ErrInvalidCoinSelection = errors.New("invalid coin selection parameters")
+
+	// ErrInsufficientFunds indicates that the UTXOs of an account are not
+	// enough to pay a target amount and the corresponding fee.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrUnknownScriptType indicates that a script type is not one of p2pkh,
+	// p2sh, p2wpkh, p2wsh or p2tr.
+	ErrUnknownScriptType = errors.New("unknown script type")
+
+	// ErrBatchTooLarge indicates that a batch request exceeds the maximum
+	// number of items that can be processed at once.
+	ErrBatchTooLarge = errors.New("batch too large")
+
+	// ErrInvalidGapLimit indicates that a gap limit is out of the accepted
+	// range.
+	ErrInvalidGapLimit = errors.New("invalid gap limit")
+
+	// ErrHealthDegraded indicates that the node breaches the configured
+	// health thresholds, for ex because its chain tip is stale.
+	ErrHealthDegraded = errors.New("node health degraded")
+
+	// ErrRPCMethodNotAllowed indicates that an RPC method cannot be called
+	// through the RPC passthrough, since it is not allowlisted.
+	ErrRPCMethodNotAllowed = errors.New("RPC method not allowed")
+
+	// ErrInvalidLogLevel indicates that a log level is not one of debug, info,
+	// warn or error.
+	ErrInvalidLogLevel = errors.New("invalid log level")
+
+	// ErrImportUnsupported indicates that the wallet supports neither
+	// importdescriptors nor importmulti.
+	ErrImportUnsupported = errors.New("wallet supports neither importdescriptors nor importmulti, " +
+		"recreate it as a descriptor wallet")
+
+	// ErrAccountNotFound indicates that no configured account has the given
+	// label.
+	ErrAccountNotFound = errors.New("account not found")
+
+	// ErrReimportInProgress indicates that an account could not be reimported,
+	// since the wallet is already being scanned.
+	ErrReimportInProgress = errors.New("wallet scan already in progress")
+
+	// ErrDescriptorNotRanged indicates that an address was requested at an
+	// index of a descriptor that has no wildcard.
+	ErrDescriptorNotRanged = errors.New("descriptor is not ranged")
+
+	// ErrIndexOutOfRange indicates that a derivation index is negative, or
+	// beyond the non-hardened BIP32 indexes.
+	ErrIndexOutOfRange = errors.New("derivation index out of range")
+
+	// ErrNoUnusedAddress indicates that all the addresses of a descriptor
+	// within its imported range have already been used.
+	ErrNoUnusedAddress = errors.New("no unused address within the imported range")
+
+	// ErrInvalidPackage indicates that a package of transactions is empty,
+	// or has more transactions than allowed by Bitcoin Core.
+	ErrInvalidPackage = errors.New("invalid transaction package")
+
+	// ErrDescriptorWalletRequired indicates that the descriptors of the
+	// wallet cannot be listed, since it is a legacy wallet.
+	ErrDescriptorWalletRequired = errors.New("operation requires a descriptor wallet")
+
+	// ErrTxIndexRequired indicates that a transaction was not found, and may
+	// only be found with the transaction index of the node enabled.
+	ErrTxIndexRequired = errors.New("transaction not found in the mempool, " +
+		"set txindex=1 in bitcoin.conf to look up confirmed transactions")
 )
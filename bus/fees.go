@@ -0,0 +1,279 @@
+package bus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/ledgerhq/satstack/types"
+	"github.com/ledgerhq/satstack/utils"
+)
+
+// FeeUnit represents the unit in which fee rates are reported by the fees
+// endpoint.
+type FeeUnit string
+
+const (
+	// SatPerKB is a FeeUnit to report fee rates in satoshis per kilo virtual
+	// byte. This is the default unit, expected by libcore.
+	SatPerKB FeeUnit = "sat/kvb"
+
+	// SatPerVByte is a FeeUnit to report fee rates in satoshis per virtual
+	// byte, as preferred by most modern clients.
+	SatPerVByte FeeUnit = "sat/vb"
+)
+
+// ParseFeeUnit validates a fee unit, regardless of its case. An empty unit
+// defaults to SatPerKB.
+func ParseFeeUnit(unit string) (FeeUnit, error) {
+	switch u := FeeUnit(strings.ToLower(unit)); u {
+	case "", "sat/kb":
+		return SatPerKB, nil
+	case SatPerKB, SatPerVByte:
+		return u, nil
+	default:
+		return "", fmt.Errorf("%w: '%s'", ErrInvalidFeeUnit, unit)
+	}
+}
+
+// PriorityFee represents the structure of payload returned by the
+// GetPriorityFee service method.
+type PriorityFee struct {
+	FeeRate     float64 `json:"fee_rate"` // sat/vB
+	LastUpdated int32   `json:"last_updated"`
+}
+
+// SmoothedFeeMode is a fee estimation mode that reports a moving average of
+// the CONSERVATIVE estimates, instead of the latest one.
+const SmoothedFeeMode = "SMOOTHED"
+
+// feeSmoothingFactor is the weight of the latest estimate in the exponential
+// moving average of the estimates. Lower values give more stable fees, that
+// are slower to follow the fee market.
+const feeSmoothingFactor = 0.3
+
+// feeAverage is the moving average of the fee estimates for a confirmation
+// target, along with the height of the last estimate it includes.
+type feeAverage struct {
+	feeRate float64 // sat/kvB
+	height  int64
+}
+
+// SmoothedFee returns the exponential moving average of the CONSERVATIVE fee
+// estimates for the given confirmation target, in satoshis per kB, as of the
+// given chain height.
+//
+// The average takes in at most one estimate per block, so that it smooths
+// the estimates across recent blocks, regardless of how often it is polled.
+// It is kept in memory, and starts over from the latest estimate on restart.
+//
+// The estimate is requested without holding feeAveragesMu, so that a slow
+// node does not block the callers of other targets.
+func (b *Bus) SmoothedFee(target int64, height int64) btcutil.Amount {
+	if average, ok := b.feeAverage(target); ok && average.height >= height {
+		return btcutil.Amount(math.Round(average.feeRate))
+	}
+
+	fee, estimateErr := b.estimateSmartFee(target, "CONSERVATIVE")
+
+	b.feeAveragesMu.Lock()
+	defer b.feeAveragesMu.Unlock()
+
+	// The average may have been updated by a concurrent call while the
+	// estimate was requested, in which case it already includes this block.
+	average, ok := b.feeAverages[target]
+	if ok && average.height >= height {
+		return btcutil.Amount(math.Round(average.feeRate))
+	}
+
+	// Failed estimations are not averaged in.
+	if estimateErr != nil {
+		if ok {
+			return btcutil.Amount(math.Round(average.feeRate))
+		}
+
+		return fallbackFee
+	}
+
+	feeRate := float64(fee)
+	if ok {
+		feeRate = feeSmoothingFactor*feeRate + (1-feeSmoothingFactor)*average.feeRate
+	}
+
+	if b.feeAverages == nil {
+		b.feeAverages = make(map[int64]feeAverage)
+	}
+
+	b.feeAverages[target] = feeAverage{feeRate: feeRate, height: height}
+
+	return btcutil.Amount(math.Round(feeRate))
+}
+
+// feeAverage returns the moving average of the fee estimates for the given
+// confirmation target, if any.
+func (b *Bus) feeAverage(target int64) (feeAverage, bool) {
+	b.feeAveragesMu.Lock()
+	defer b.feeAveragesMu.Unlock()
+
+	average, ok := b.feeAverages[target]
+	return average, ok
+}
+
+// MempoolMinFee returns the minimum fee rate for a transaction to be accepted
+// in the mempool of the node, in satoshis per kB.
+//
+// The value is never lower than the minimum relay fee of the node.
+func (b *Bus) MempoolMinFee() (btcutil.Amount, error) {
+	info, err := b.GetMempoolInfo()
+	if err != nil {
+		return 0, err
+	}
+
+	return utils.ParseSatoshi(info.MempoolMinFee), nil
+}
+
+// IncrementalRelayFee returns the minimum fee rate increase for mempool
+// replacements, as defined by BIP125.
+func (b *Bus) IncrementalRelayFee() (types.FeeRate, error) {
+	result, err := b.mainClient.RawRequest("getnetworkinfo", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var info struct {
+		IncrementalFee float64 `json:"incrementalfee"`
+	}
+
+	if err := json.Unmarshal(result, &info); err != nil {
+		return 0, err
+	}
+
+	return types.FeeRateFromBTCPerKB(info.IncrementalFee)
+}
+
+// MinRelayTxFee returns the minimum fee rate for a transaction to be relayed
+// by the node, as configured by its minrelaytxfee option.
+func (b *Bus) MinRelayTxFee() (types.FeeRate, error) {
+	result, err := b.mainClient.RawRequest("getnetworkinfo", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var info struct {
+		RelayFee float64 `json:"relayfee"`
+	}
+
+	if err := json.Unmarshal(result, &info); err != nil {
+		return 0, err
+	}
+
+	return types.FeeRateFromBTCPerKB(info.RelayFee)
+}
+
+// dustRelayFeeMultiplier is the ratio between the fee rate used to compute
+// dust thresholds and minrelaytxfee. Bitcoin Core does not expose its
+// dustrelayfee option over RPC, but its default of 3000 sat/kvB is 3 times
+// the default minrelaytxfee.
+const dustRelayFeeMultiplier = 3
+
+// dustVSizes maps the supported script types to the size of an output paying
+// to them, plus the size of an input spending it, as estimated by Bitcoin
+// Core in GetDustThreshold.
+var dustVSizes = map[types.ScriptType]int64{
+	types.ScriptTypeP2PKH:  34 + 148,
+	types.ScriptTypeP2SH:   32 + 148,
+	types.ScriptTypeP2WPKH: 31 + 67,
+	types.ScriptTypeP2WSH:  43 + 67,
+	types.ScriptTypeP2TR:   43 + 67,
+}
+
+// DustThreshold returns the smallest value of an output of the given script
+// type (p2pkh, p2sh, p2wpkh, p2wsh or p2tr) that is not considered dust, ie
+// that is worth more than the fee required to spend it. Outputs below the
+// threshold are not relayed by the node.
+func (b *Bus) DustThreshold(scriptType string) (btcutil.Amount, error) {
+	vsize, ok := dustVSizes[types.ScriptType(strings.ToLower(scriptType))]
+	if !ok {
+		return 0, fmt.Errorf("%w: '%s'", ErrUnknownScriptType, scriptType)
+	}
+
+	relayFee, err := b.MinRelayTxFee()
+	if err != nil {
+		return 0, err
+	}
+
+	dustRelayFee := relayFee.SatPerKB() * dustRelayFeeMultiplier
+
+	return dustRelayFee * btcutil.Amount(vsize) / 1000, nil
+}
+
+// BumpFeeEstimate computes the fee required to replace an unconfirmed
+// transaction at the given fee rate (in sat/vB), assuming the replacement has
+// the same virtual size as the original.
+//
+// As per BIP125, the replacement must pay at least the fees of the original
+// transaction and its descendants, plus the incremental relay fee for its
+// own size. The result does not involve any signing.
+func (b *Bus) BumpFeeEstimate(txid string, targetFeeRate float64) (*types.BumpFeeResult, error) {
+	entry, err := b.GetMempoolEntry(txid)
+	if errors.Is(err, ErrNotInMempool) {
+		if confirmed, _ := b.isConfirmed(txid); confirmed {
+			return nil, fmt.Errorf("%w: %s", ErrTransactionConfirmed, txid)
+		}
+
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.BIP125Replaceable {
+		return nil, fmt.Errorf("%w: %s", ErrNotReplaceable, txid)
+	}
+
+	incrementalFee, err := b.IncrementalRelayFee()
+	if err != nil {
+		return nil, err
+	}
+
+	originalFee := utils.ParseSatoshi(entry.Fees.Base)
+	replacedFees := utils.ParseSatoshi(entry.Fees.Descendant)
+
+	vsize := float64(entry.VSize)
+	targetFee := btcutil.Amount(math.Ceil(targetFeeRate * vsize))
+	minFee := replacedFees + btcutil.Amount(math.Ceil(incrementalFee.SatPerVByte()*vsize))
+
+	requiredFee := targetFee
+	if requiredFee < minFee {
+		requiredFee = minFee
+	}
+
+	return &types.BumpFeeResult{
+		TxID:            txid,
+		VSize:           entry.VSize,
+		OriginalFee:     originalFee,
+		RequiredFee:     requiredFee,
+		AdditionalFee:   requiredFee - originalFee,
+		RequiredFeeRate: float64(requiredFee) / vsize,
+	}, nil
+}
+
+// isConfirmed reports whether a wallet transaction has at least one
+// confirmation.
+func (b *Bus) isConfirmed(txid string) (bool, error) {
+	chainHash, err := utils.ParseChainHash(txid)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := b.mainClient.GetTransactionWatchOnly(chainHash, true)
+	if err != nil {
+		return false, err
+	}
+
+	return tx.Confirmations > 0, nil
+}
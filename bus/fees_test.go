@@ -0,0 +1,134 @@
+package bus
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+func TestParseFeeUnit(t *testing.T) {
+	tests := []struct {
+		unit string
+		want FeeUnit
+		err  error
+	}{
+		{unit: "", want: SatPerKB},
+		{unit: "sat/kb", want: SatPerKB},
+		{unit: "sat/kvb", want: SatPerKB},
+		{unit: "SAT/KVB", want: SatPerKB},
+		{unit: "sat/vb", want: SatPerVByte},
+		{unit: "sat/vB", want: SatPerVByte},
+		{unit: "btc/kb", err: ErrInvalidFeeUnit},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFeeUnit(tt.unit)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("ParseFeeUnit(%q) error = %v, want %v", tt.unit, err, tt.err)
+		}
+
+		if got != tt.want {
+			t.Errorf("ParseFeeUnit(%q) = %q, want %q", tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestSmoothedFee(t *testing.T) {
+	node, client := newRecordingNode(t, map[string]string{
+		"estimatesmartfee": `{"feerate":0.0001,"blocks":2}`,
+	})
+
+	b := &Bus{mainClient: client}
+
+	steps := []struct {
+		height  int64
+		feeRate string
+		want    btcutil.Amount
+	}{
+		{height: 100, feeRate: `0.0001`, want: 10000},
+		{height: 100, feeRate: `0.0002`, want: 10000},    // at most one estimate per block
+		{height: 101, feeRate: `0.0002`, want: 13000},    // 0.3 * 20000 + 0.7 * 10000
+		{height: 102, feeRate: `-1`, want: 13000},        // failed estimates are not averaged in
+		{height: 103, feeRate: `0.00000001`, want: 9100}, // 0.3 * 1 + 0.7 * 13000, the fallback fee
+	}
+
+	for i, step := range steps {
+		if step.feeRate == `-1` {
+			node.setResult("estimatesmartfee", `{"errors":["Insufficient data or no feerate found"],"blocks":0}`)
+		} else {
+			node.setResult("estimatesmartfee", `{"feerate":`+step.feeRate+`,"blocks":2}`)
+		}
+
+		if got := b.SmoothedFee(2, step.height); got != step.want {
+			t.Errorf("step %d: SmoothedFee(2, %d) = %d, want %d", i, step.height, got, step.want)
+		}
+	}
+
+	// Without an average to fall back to, failed estimates return the
+	// fallback fee.
+	node.setResult("estimatesmartfee", `{"errors":["Insufficient data or no feerate found"],"blocks":0}`)
+	if got := b.SmoothedFee(6, 103); got != fallbackFee {
+		t.Errorf("SmoothedFee(6, 103) = %d, want %d", got, fallbackFee)
+	}
+
+	if calls := node.calls("getblockcount"); len(calls) != 0 {
+		t.Errorf("getblockcount called %d times, want 0", len(calls))
+	}
+}
+
+func TestSmoothedFeeDoesNotLockDuringEstimate(t *testing.T) {
+	estimating := make(chan struct{})
+	release := make(chan struct{})
+
+	connCfg := serveStubNode(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+
+		result := `100`
+		if strings.Contains(string(body), `"estimatesmartfee"`) {
+			close(estimating)
+			<-release
+			result = `{"feerate":0.0001,"blocks":2}`
+		}
+
+		_, _ = io.WriteString(w, `{"result":`+result+`,"error":null,"id":1}`)
+	}), false)
+
+	client, err := newRPCClient(connCfg, transportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Shutdown)
+
+	b := &Bus{mainClient: client}
+
+	done := make(chan btcutil.Amount)
+	go func() { done <- b.SmoothedFee(2, 100) }()
+
+	<-estimating
+
+	// The average of another target can be read while the estimate is
+	// pending.
+	read := make(chan struct{})
+	go func() {
+		b.feeAverage(6)
+		close(read)
+	}()
+
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("feeAveragesMu held during estimatesmartfee")
+	}
+
+	close(release)
+
+	if got := <-done; got != 10000 {
+		t.Errorf("SmoothedFee(2, 100) = %d, want 10000", got)
+	}
+}
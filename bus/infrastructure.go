@@ -2,11 +2,17 @@ package bus
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
@@ -15,6 +21,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/ledgerhq/satstack/config"
+	"github.com/ledgerhq/satstack/types"
 	"github.com/ledgerhq/satstack/utils"
 	"github.com/ledgerhq/satstack/version"
 	"github.com/patrickmn/go-cache"
@@ -26,20 +33,30 @@ const (
 	// import in the Bitcoin wallet.
 	defaultAccountDepth = 1000
 
+	// rescanWindow is the number of seconds before a descriptor timestamp
+	// from which Bitcoin Core starts scanning blocks.
+	rescanWindow = 7200
+
 	// connPoolSize indicates the number of *rpcclient.Client objects that
 	// are available to use for communicating to the Bitcoin node.
 	//
 	// Set this to the maximum number of concurrent RPC operations that may be
 	// performed on the Bitcoin node.
-	connPoolSize = 2
+	connPoolSize = 4
 
 	// minimumSupportedBitcoindVersion indicates the minimum version that is
 	// supported by SatStack.
 	minSupportedBitcoindVersion = 220000
 
-	// walletName indicates the name of the wallet created by SatStack in
-	// bitcoind's wallet.
-	walletName = "satstack"
+	// minWarmupBackoff and maxWarmupBackoff bound the delay between RPC
+	// retries while bitcoind is warming up (loading the block index,
+	// verifying blocks, etc).
+	minWarmupBackoff = 1 * time.Second
+	maxWarmupBackoff = 30 * time.Second
+
+	// defaultWalletName indicates the name of the wallet created by SatStack
+	// in bitcoind's wallet, unless overridden in the config.
+	defaultWalletName = "satstack"
 
 	errDuplicateWalletLoadMsg    = "Duplicate -wallet filename specified."
 	errWalletAlreadyLoadedMsgOld = "Wallet file verification failed. Refusing to load database. Data file"
@@ -59,11 +76,18 @@ var (
 // concurrent invocation of RPC methods.
 type Bus struct {
 	// Informational fields
-	Chain       string
-	Pruned      bool
-	TxIndex     bool
-	BlockFilter bool
-	Currency    Currency // Based on Chain value, for interoperability with libcore
+	Chain          string
+	Pruned         bool
+	TxIndex        bool
+	BlockFilter    bool
+	Currency       Currency     // Based on Chain value, for interoperability with libcore
+	NodeVersion    int32        // Version of the connected bitcoind, ex: 220000
+	NodeSubversion string       // User agent of the connected bitcoind, ex: /Satoshi:22.0.0/
+	WalletName     string       // Name of the bitcoind wallet used by SatStack
+	WalletFormat   WalletFormat // Format of the wallet, which determines the import RPC
+
+	// Whether to create the wallet if it does not exist on the node.
+	createWallet bool
 
 	// Thread-safe Bus cache, to query results typically by hash
 	Cache *cache.Cache
@@ -71,6 +95,9 @@ type Bus struct {
 	// Config to use for creating new connections on-demand.
 	connCfg *rpcclient.ConnConfig
 
+	// Customizations of the HTTP transport used by RPC clients.
+	transport transportOptions
+
 	// Primary RPC client for JSON-RPC requests. This does NOT allow batch
 	// requests.
 	mainClient *rpcclient.Client
@@ -81,6 +108,46 @@ type Bus struct {
 	// RPC client reserved for performing RPC-based cleanups.
 	janitorClient *rpcclient.Client
 
+	// Pool of long-lived RPC clients, shared by service methods.
+	pool *clientPool
+
+	// walletLoading is set while the wallet is being loaded (or created) in
+	// the background by LoadWalletAsync.
+	walletLoading atomic.Bool
+
+	// walletMissing is set once LoadWalletAsync found that the wallet does
+	// not exist, and is not allowed to create it. The load is not retried.
+	walletMissing atomic.Bool
+
+	// Number of blocks to rescan per rescanblockchain call. If not positive,
+	// the whole range is rescanned at once.
+	rescanChunkSize int64
+
+	// rescanning is set while rescanWallet is running.
+	rescanning atomic.Bool
+
+	// rescanAborted is set by AbortRescan, to stop chunked rescans.
+	rescanAborted atomic.Bool
+
+	// accounts of the configuration, replaced on config reload.
+	accounts atomic.Pointer[[]config.Account]
+
+	// reimporting is set while ReimportAccount or ImportAddedAccounts is
+	// running.
+	reimporting atomic.Bool
+
+	// importProgress holds the last known progress of the descriptor import
+	// performed by the worker. It is nil if unknown, or if no import is in
+	// progress.
+	importProgress atomic.Pointer[ScanProgress]
+
+	// scanningTxOutSet is set while ScanTxOutSet is running.
+	scanningTxOutSet atomic.Bool
+
+	// supply holds the result of the last circulating supply check, if one
+	// was performed. It is nil until runTheNumbers completes successfully.
+	supply atomic.Pointer[types.SupplyReport]
+
 	// btcd network params
 	Params *chaincfg.Params
 
@@ -91,42 +158,62 @@ type Bus struct {
 	// This value can be exported for use by other packages to avoid making
 	// explorer requests before satstack is able to serve them.
 	IsPendingScan bool
+
+	// descriptorDrift holds the result of the last reconciliation between
+	// the wallet descriptors and the config. It is nil until checked.
+	descriptorDrift atomic.Pointer[DescriptorDrift]
+
+	// feeAverages holds the moving averages of fee estimates reported by
+	// SmoothedFee, by confirmation target. feeAveragesMu guards it.
+	feeAveragesMu sync.Mutex
+	feeAverages   map[int64]feeAverage
+
+	// accountSummaries caches the results of GetAccountSummary, without
+	// balances, by descriptors and number of derived addresses.
+	// accountSummariesMu guards it.
+	accountSummariesMu sync.Mutex
+	accountSummaries   map[string]accountSummaryEntry
 }
 
 type descriptor struct {
 	Value string
 	Depth int
 	Age   uint32
+	Label string // Wallet label of the derived addresses, external descriptors only
 }
 
-// New initializes a Bus struct that embeds a btcd RPC client.
-func New(host string, user string, pass string, proxy string, noTLS bool, unloadWallet bool) (*Bus, error) {
+// New initializes a Bus struct that embeds a btcd RPC client, using the RPC
+// connection settings of the given configuration.
+//
+// The configured wallet indicates the name of the bitcoind wallet to use. If
+// empty, defaultWalletName is used.
+//
+// The configured currency, if not empty, must match the Currency of the chain
+// that the node is connected to. This prevents silently deriving addresses
+// for the wrong network.
+func New(configuration *config.Configuration, unloadWallet bool) (*Bus, error) {
 	log.Info("Warming up...")
 
-	// Prepare the connection config to initialize the rpcclient.Client
-	// pool with.
-	connCfg := &rpcclient.ConnConfig{
-		Host:         fmt.Sprintf("%s/wallet/%s", host, walletName),
-		User:         user,
-		Pass:         pass,
-		Proxy:        proxy,
-		HTTPPostMode: true,
-		DisableTLS:   noTLS,
+	walletName := configuredWalletName(configuration)
+
+	connCfg, transport, err := newConnConfig(configuration, walletName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Initialize RPC clients.
 	log.Info("Creating main RPC client...")
-	mainClient, err := rpcclient.New(connCfg, nil)
+	mainClient, err := newRPCClient(connCfg, transport)
 	if err != nil {
 		return nil, err // error ctx not required
 	}
 
-	secondaryClient, err := rpcclient.New(connCfg, nil)
+	secondaryClient, err := newRPCClient(connCfg, transport)
 	if err != nil {
 		return nil, err // error ctx not required
 	}
 
-	janitorClient, err := rpcclient.New(connCfg, nil)
+	janitorClient, err := newRPCClient(connCfg, transport)
 	if err != nil {
 		return nil, err // error ctx not required
 	}
@@ -142,7 +229,7 @@ func New(host string, user string, pass string, proxy string, noTLS bool, unload
 	}
 
 	log.Info("Calling custom GetBlockChainInfo...")
-	blockchainResult, err := mainClient.RawRequest("getblockchaininfo", nil)
+	blockchainResult, err := rawRequestAfterWarmup(mainClient, "getblockchaininfo", nil)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ErrBitcoindUnreachable, err)
 	}
@@ -154,8 +241,9 @@ func New(host string, user string, pass string, proxy string, noTLS bool, unload
 
 	// Custom network info struct to handle warnings as array
 	type customNetworkInfo struct {
-		Version   int32    `json:"version"`
-		Warnings  []string `json:"warnings"`
+		Version    int32    `json:"version"`
+		Subversion string   `json:"subversion"`
+		Warnings   []string `json:"warnings"`
 	}
 
 	// Use raw request to avoid btcd struct incompatibility
@@ -189,6 +277,16 @@ func New(host string, user string, pass string, proxy string, noTLS bool, unload
 		return nil, err
 	}
 
+	if expected := configuration.Currency; expected != "" && expected != currency {
+		return nil, fmt.Errorf("%s: node is on chain '%s' (%s), but config expects %s",
+			ErrChainMismatch, info.Chain, currency, expected)
+	}
+
+	params, err := ChainParams(info.Chain, configuration.SignetChallenge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain params: %w", err)
+	}
+
 	if unloadWallet {
 		if err = mainClient.UnloadWallet(nil); err != nil {
 			return nil, err
@@ -198,7 +296,9 @@ func New(host string, user string, pass string, proxy string, noTLS bool, unload
 		os.Exit(1)
 	}
 
-	isNewWallet, err = loadOrCreateWallet(mainClient)
+	createWallet := !configuration.NoCreateWallet
+
+	isNewWallet, err = loadOrCreateWallet(mainClient, walletName, createWallet)
 	if err != nil {
 		return nil, err
 	}
@@ -213,13 +313,21 @@ func New(host string, user string, pass string, proxy string, noTLS bool, unload
 		}).Info("Loaded existing wallet")
 	}
 
-	params, err := ChainParams(info.Chain)
+	walletFormat, err := detectWalletFormat(mainClient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get chain params: %w", err)
+		return nil, err
+	}
+
+	if walletFormat == LegacyWallet {
+		log.WithFields(log.Fields{
+			"wallet": walletName,
+		}).Warn("Legacy wallet detected, descriptors will be imported with importmulti. " +
+			"Recreate the wallet as a descriptor wallet, since recent Bitcoin Core versions dropped legacy wallets")
 	}
 
 	b := &Bus{
 		connCfg:         connCfg,
+		transport:       transport,
 		mainClient:      mainClient,
 		secondaryClient: secondaryClient,
 		janitorClient:   janitorClient,
@@ -228,14 +336,38 @@ func New(host string, user string, pass string, proxy string, noTLS bool, unload
 		BlockFilter:     blockFilter,
 		TxIndex:         txIndex,
 		Currency:        currency,
+		NodeVersion:     networkInfo.Version,
+		NodeSubversion:  networkInfo.Subversion,
+		WalletName:      walletName,
+		WalletFormat:    walletFormat,
+		createWallet:    createWallet,
+		rescanChunkSize: configuration.RescanChunkSize,
 		Cache:           nil, // Disabled by default
 		Params:          params,
 		IsPendingScan:   true,
 	}
 
+	b.pool = newClientPool(connPoolSize, b.ClientFactory)
+	b.SetAccounts(configuration.Accounts)
+
 	return b, nil
 }
 
+// SetAccounts sets the accounts of the configuration, against which
+// RescanNeeded checks the wallet.
+func (b *Bus) SetAccounts(accounts []config.Account) {
+	b.accounts.Store(&accounts)
+}
+
+// Accounts returns the accounts of the configuration.
+func (b *Bus) Accounts() []config.Account {
+	if accounts := b.accounts.Load(); accounts != nil {
+		return *accounts
+	}
+
+	return nil
+}
+
 // Close performs cleanup operations on the Bus, notably shutting down the
 // rpcclient.Client connections.
 //
@@ -247,6 +379,7 @@ func (b *Bus) Close(ctx context.Context) {
 	go func() {
 		b.mainClient.Shutdown()
 		b.secondaryClient.Shutdown()
+		b.pool.close()
 
 		// Only unload wallet if we are not in a pending scan
 		// otherwise the nuclear timeout corrupts the wallet state
@@ -269,8 +402,118 @@ func (b *Bus) Close(ctx context.Context) {
 
 }
 
+// configuredWalletName returns the name of the bitcoind wallet used by SatStack.
+func configuredWalletName(configuration *config.Configuration) string {
+	if configuration.Wallet == "" {
+		return defaultWalletName
+	}
+
+	return configuration.Wallet
+}
+
+// newConnConfig returns the connection config and HTTP transport options of
+// the RPC clients, scoped to the given wallet.
+func newConnConfig(configuration *config.Configuration, walletName string) (*rpcclient.ConnConfig, transportOptions, error) {
+	host := *configuration.RPCURL
+
+	// If the RPC URL points to a Unix domain socket, the host is only used
+	// to build the request URLs, while connections are dialed to the socket.
+	socketPath, isUnixSocket := unixSocketPath(host)
+	if isUnixSocket {
+		host = "localhost"
+	}
+
+	// Prepare the connection config to initialize the rpcclient.Client
+	// pool with. All RPCs, including the wallet ones, go to the endpoint of
+	// the configured wallet. Wallet names may contain characters such as
+	// spaces, which bitcoind expects to be escaped in the URL.
+	connCfg := &rpcclient.ConnConfig{
+		Host:         fmt.Sprintf("%s/wallet/%s", host, url.PathEscape(walletName)),
+		Proxy:        configuration.TorProxy,
+		HTTPPostMode: true,
+		DisableTLS:   !configuration.TLSEnabled(),
+	}
+
+	// Unix domain sockets are typically served by a plain HTTP proxy, so TLS
+	// is only used over them if explicitly requested.
+	if isUnixSocket && configuration.RPCTLS == nil {
+		connCfg.DisableTLS = true
+	}
+
+	if path := configuration.RPCCACertPath; path != "" {
+		cert, err := os.ReadFile(path)
+		if err != nil {
+			return nil, transportOptions{}, fmt.Errorf("%s: %w", ErrInvalidCACert, err)
+		}
+
+		if !x509.NewCertPool().AppendCertsFromPEM(cert) {
+			return nil, transportOptions{}, fmt.Errorf("%s: no PEM certificate found in %s",
+				ErrInvalidCACert, path)
+		}
+
+		connCfg.Certificates = cert
+	}
+
+	transport := transportOptions{
+		socketPath:         socketPath,
+		insecureSkipVerify: configuration.RPCTLSInsecureSkipVerify,
+	}
+
+	if transport.insecureSkipVerify {
+		log.Warn("TLS certificate verification of the RPC server is disabled")
+	}
+
+	// The cookie file is rewritten by bitcoind on every restart, so we only
+	// record its path here. rpcclient reads it again whenever it changes,
+	// so clients returned by ClientFactory always use fresh credentials.
+	if configuration.RPCCookiePath != "" {
+		connCfg.CookiePath = configuration.RPCCookiePath
+	} else {
+		connCfg.User = *configuration.RPCUser
+		connCfg.Pass = *configuration.RPCPassword
+	}
+
+	return connCfg, transport, nil
+}
+
+// ClientFactory creates a new RPC client, isolated from the pool. The caller
+// is responsible for shutting it down.
 func (b *Bus) ClientFactory() (*rpcclient.Client, error) {
-	return rpcclient.New(b.connCfg, nil)
+	return newRPCClient(b.connCfg, b.transport)
+}
+
+// isWarmingUp reports whether err is the RPC error returned by bitcoind while
+// it is still starting up, for ex with "Loading block index..." or
+// "Verifying blocks..." messages.
+func isWarmingUp(err error) bool {
+	var rpcErr *btcjson.RPCError
+	return errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCInWarmup
+}
+
+// rawRequestAfterWarmup performs a raw RPC request, retrying with an
+// exponential backoff for as long as bitcoind reports that it is warming up.
+//
+// Any other error is returned as is.
+func rawRequestAfterWarmup(client *rpcclient.Client, method string, params []json.RawMessage) (json.RawMessage, error) {
+	backoff := minWarmupBackoff
+
+	for {
+		result, err := client.RawRequest(method, params)
+		if !isWarmingUp(err) {
+			return result, err
+		}
+
+		log.WithFields(log.Fields{
+			"error": err,
+			"retry": backoff,
+		}).Info("Waiting for bitcoind to warm up")
+
+		time.Sleep(backoff)
+
+		if backoff *= 2; backoff > maxWarmupBackoff {
+			backoff = maxWarmupBackoff
+		}
+	}
 }
 
 // Currency represents the currency type (btc) and the network params
@@ -284,9 +527,13 @@ const (
 
 // currencyFromChain is an adapter function to convert a chain (network) value
 // to a Currency type that's understood by libcore.
+//
+// libcore has no notion of signet or regtest, so these are mapped to the
+// testnet currency, which shares the same address encoding (except for the
+// bech32 HRP on regtest).
 func CurrencyFromChain(chain string) (Currency, error) {
 	switch chain {
-	case "regtest", "test":
+	case "regtest", "test", "signet":
 		return Testnet, nil
 	case "main":
 		return Mainnet, nil
@@ -298,14 +545,30 @@ func CurrencyFromChain(chain string) (Currency, error) {
 // ChainParams returns the *chaincfg.Params instance corresponding to the
 // network that the underlying node is connected to.
 //
+// On signet, the hex signetChallenge selects the params of a custom signet,
+// whose network magic is derived from the challenge. If empty, the params of
+// the default global signet are returned.
+//
 // This value is useful for several operations in btcd, and can be accessed
 // via the Bus struct.
-func ChainParams(chain string) (*chaincfg.Params, error) {
+func ChainParams(chain string, signetChallenge string) (*chaincfg.Params, error) {
 	switch chain {
 	case "regtest":
 		return &chaincfg.RegressionNetParams, nil
 	case "test":
 		return &chaincfg.TestNet3Params, nil
+	case "signet":
+		if signetChallenge == "" {
+			return &chaincfg.SigNetParams, nil
+		}
+
+		challenge, err := hex.DecodeString(signetChallenge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signet challenge: %w", err)
+		}
+
+		params := chaincfg.CustomSignetParams(challenge, nil)
+		return &params, nil
 	case "main":
 		return &chaincfg.MainNetParams, nil
 	default:
@@ -325,11 +588,15 @@ type CreateWalletResult struct {
 // Bitcoin node, and returns an error in such a case. This is typically the
 // case when the option disablewallet=1 is specified in bitcoin.conf.
 //
+// If create is false, ErrWalletNotFound is returned instead of creating the
+// wallet. This is useful for operators who manage wallets externally.
+//
 // The function returns a bool to indicate whether the wallet was created
 // (true) or loaded (false). The value is meaningless if an error is returned.
 //
-// In case a new wallet is created, it'll be in loaded state by default.
-func loadOrCreateWallet(client *rpcclient.Client) (bool, error) {
+// In case a new wallet is created, it'll be in loaded state by default. It is
+// a blank, watch-only descriptor wallet.
+func loadOrCreateWallet(client *rpcclient.Client, walletName string, create bool) (bool, error) {
 	// Try to load wallet first.
 	_, err := client.LoadWallet(walletName)
 	if err == nil {
@@ -337,7 +604,10 @@ func loadOrCreateWallet(client *rpcclient.Client) (bool, error) {
 	}
 
 	// Convert native error to btcjson.RPCError
-	rpcErr := err.(*btcjson.RPCError)
+	rpcErr, ok := err.(*btcjson.RPCError)
+	if !ok {
+		return false, fmt.Errorf("%s: %w", ErrLoadWallet, err)
+	}
 
 	// Check if wallet RPC is disabled.
 	if rpcErr.Code == btcjson.ErrRPCMethodNotFound.Code {
@@ -359,6 +629,10 @@ func loadOrCreateWallet(client *rpcclient.Client) (bool, error) {
 		return false, nil
 	}
 
+	if rpcErr.Code == btcjson.ErrRPCWalletNotFound && !create {
+		return false, fmt.Errorf("%w: %s", ErrWalletNotFound, walletName)
+	}
+
 	// Wallet to load could not be found - create it.
 	if rpcErr.Code == btcjson.ErrRPCWalletNotFound {
 
@@ -488,14 +762,14 @@ func blockFilterEnabled(client *rpcclient.Client, hash string) (bool, error) {
 func (b *Bus) UnloadWallet() {
 	if err := b.janitorClient.UnloadWallet(nil); err != nil {
 		log.WithFields(log.Fields{
-			"wallet": walletName,
+			"wallet": b.WalletName,
 			"error":  err,
 		}).Warn("Unable to unload wallet")
 		return
 	}
 
 	log.WithFields(log.Fields{
-		"wallet": walletName,
+		"wallet": b.WalletName,
 	}).Info("Unloaded wallet successfully")
 
 	b.janitorClient.Shutdown()
@@ -508,17 +782,38 @@ func (b *Bus) DumpLatestRescanTime() error {
 	if err != nil {
 		log.WithFields(log.Fields{
 			"prefix": "worker",
-		}).Error("Error fetching blockheight: %s", err)
+			"error":  err,
+		}).Error("Error fetching blockheight")
 		return err
 
 	}
+
+	// The wallet follows the chain since the last rescan, so the rescanned
+	// range extends up to the current height for the descriptors it covers.
+	// The range of an aborted rescan covers no descriptors, and keeps
+	// covering none.
+	var rescanStart, rescanTime *int64
+	if previous, err := config.LoadRescanConf(); err == nil {
+		rescanStart = previous.RescanStart
+		rescanTime = previous.RescanTime
+	}
+
+	return b.dumpRescanCheckpoint(rescanStart, rescanTime, currentHeight)
+}
+
+// dumpRescanCheckpoint saves the height of the last block that the wallet
+// was rescanned up to into the rescan checkpoint file, along with the first
+// block of the rescanned range and the time its rescan began, if known.
+func (b *Bus) dumpRescanCheckpoint(rescanStart *int64, rescanTime *int64, height int64) error {
 	data := &config.ConfigurationRescan{
 		TimeStamp:       strconv.Itoa(int(time.Now().Unix())),
 		LastSyncTime:    time.Now().Format(time.ANSIC),
-		LastBlock:       currentHeight,
+		LastBlock:       height,
+		RescanStart:     rescanStart,
+		RescanTime:      rescanTime,
 		SatstackVersion: version.Version,
 	}
-	err = config.WriteRescanConf(data)
+	err := config.WriteRescanConf(data)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"prefix": "worker",
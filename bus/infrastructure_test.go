@@ -0,0 +1,146 @@
+package bus
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ledgerhq/satstack/config"
+)
+
+func TestCurrencyFromChain(t *testing.T) {
+	tests := []struct {
+		chain    string
+		currency Currency
+		err      error
+	}{
+		{chain: "main", currency: Mainnet},
+		{chain: "test", currency: Testnet},
+		{chain: "signet", currency: Testnet},
+		{chain: "regtest", currency: Testnet},
+		{chain: "testnet4", err: ErrUnrecognizedChain},
+		{chain: "", err: ErrUnrecognizedChain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chain, func(t *testing.T) {
+			currency, err := CurrencyFromChain(tt.chain)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("CurrencyFromChain(%q) error = %v, want %v", tt.chain, err, tt.err)
+			}
+
+			if currency != tt.currency {
+				t.Errorf("CurrencyFromChain(%q) = %q, want %q", tt.chain, currency, tt.currency)
+			}
+		})
+	}
+}
+
+func TestChainParams(t *testing.T) {
+	tests := []struct {
+		chain  string
+		params *chaincfg.Params
+		err    error
+	}{
+		{chain: "main", params: &chaincfg.MainNetParams},
+		{chain: "test", params: &chaincfg.TestNet3Params},
+		{chain: "signet", params: &chaincfg.SigNetParams},
+		{chain: "regtest", params: &chaincfg.RegressionNetParams},
+		{chain: "testnet4", err: ErrUnrecognizedChain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chain, func(t *testing.T) {
+			params, err := ChainParams(tt.chain, "")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ChainParams(%q) error = %v, want %v", tt.chain, err, tt.err)
+			}
+
+			if tt.params == nil {
+				return
+			}
+
+			if params.Name != tt.params.Name || params.Net != tt.params.Net {
+				t.Errorf("ChainParams(%q) = %s (%s), want %s (%s)", tt.chain,
+					params.Name, params.Net, tt.params.Name, tt.params.Net)
+			}
+
+			if params.Bech32HRPSegwit != tt.params.Bech32HRPSegwit {
+				t.Errorf("ChainParams(%q) HRP = %q, want %q", tt.chain,
+					params.Bech32HRPSegwit, tt.params.Bech32HRPSegwit)
+			}
+		})
+	}
+}
+
+// deriveKey derives the given path from an extended key.
+func deriveKey(t *testing.T, key *hdkeychain.ExtendedKey, path ...uint32) *hdkeychain.ExtendedKey {
+	t.Helper()
+
+	for _, index := range path {
+		var err error
+		if key, err = key.Derive(index); err != nil {
+			t.Fatalf("Derive(%d) error = %v", index, err)
+		}
+	}
+
+	return key
+}
+
+func TestSignetAddresses(t *testing.T) {
+	const hardened = hdkeychain.HardenedKeyStart
+
+	// OP_TRUE, a challenge that any block solves.
+	const customChallenge = "51"
+
+	tests := []struct {
+		name      string
+		challenge string
+	}{
+		{name: "default challenge"},
+		{name: "custom challenge", challenge: customChallenge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ChainParams("signet", tt.challenge)
+			if err != nil {
+				t.Fatalf("ChainParams(signet, %q) error = %v", tt.challenge, err)
+			}
+
+			if tt.challenge != "" && params.Net == chaincfg.SigNetParams.Net {
+				t.Errorf("custom signet has the network magic of the default one")
+			}
+
+			seed := bytes.Repeat([]byte{0x42}, hdkeychain.RecommendedSeedLen)
+			master, err := hdkeychain.NewMaster(seed, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			accountKey, err := deriveKey(t, master, hardened+84, hardened+1, hardened+0).Neuter()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tpub := accountKey.String()
+			if !strings.HasPrefix(tpub, "tpub") {
+				t.Fatalf("account key = %s, want a tpub", tpub)
+			}
+
+			// The account key is accepted as-is, for the receive and change
+			// descriptors.
+			external, internal, err := accountDescriptors(config.Account{XPub: &tpub, Scheme: "native_segwit"}, params)
+			if err != nil {
+				t.Fatalf("accountDescriptors() error = %v", err)
+			}
+
+			if external != "wpkh("+tpub+"/0/*)" || internal != "wpkh("+tpub+"/1/*)" {
+				t.Errorf("accountDescriptors() = %s, %s", external, internal)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package bus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/ledgerhq/satstack/types"
+	"github.com/ledgerhq/satstack/utils"
+)
+
+// maxPackageCount is the maximum number of transactions in a package, as
+// enforced by Bitcoin Core.
+const maxPackageCount = 25
+
+// GetMempoolInfo returns information about the current state of the mempool
+// of the node.
+func (b *Bus) GetMempoolInfo() (*types.MempoolInfo, error) {
+	result, err := b.mainClient.RawRequest("getmempoolinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info types.MempoolInfo
+	if err := json.Unmarshal(result, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetMempoolEntry returns the mempool data of the given transaction.
+//
+// If the transaction is not in the mempool, either because it is confirmed
+// or unknown to the node, ErrNotInMempool is returned.
+func (b *Bus) GetMempoolEntry(txid string) (*types.MempoolEntry, error) {
+	txidJSON, err := json.Marshal(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("getmempoolentry",
+		[]json.RawMessage{txidJSON})
+	if err != nil {
+		if rpcErr, ok := err.(*btcjson.RPCError); ok &&
+			rpcErr.Code == btcjson.ErrRPCInvalidAddressOrKey {
+			return nil, fmt.Errorf("%w: %s", ErrNotInMempool, txid)
+		}
+
+		return nil, err
+	}
+
+	var entry types.MempoolEntry
+	if err := json.Unmarshal(result, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// IsOutputSpent reports whether the given transaction output is spent, so
+// that clients can tell whether a UTXO is still spendable. Unknown outputs
+// are reported as spent, since gettxout does not tell them apart.
+//
+// If includeMempool is set, outputs spent by mempool transactions are
+// reported as spent. Otherwise, only spends confirmed in the chain count, and
+// the outputs of mempool transactions are reported as unspent.
+func (b *Bus) IsOutputSpent(txid string, vout uint32, includeMempool bool) (bool, error) {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrMalformedChainHash, err)
+	}
+
+	txOut, err := b.mainClient.GetTxOut(hash, vout, includeMempool)
+	if err != nil {
+		return false, err
+	}
+
+	if txOut != nil {
+		return false, nil
+	}
+
+	if includeMempool {
+		return true, nil
+	}
+
+	// Outputs of mempool transactions are not in the chain UTXO set, but
+	// they are not spent by any confirmed transaction either.
+	_, err = b.GetMempoolEntry(txid)
+	switch {
+	case errors.Is(err, ErrNotInMempool):
+		return true, nil
+	case err != nil:
+		return false, err
+	}
+
+	tx, err := b.mainClient.GetRawTransaction(hash)
+	if err != nil {
+		return false, err
+	}
+
+	return vout >= uint32(len(tx.MsgTx().TxOut)), nil
+}
+
+// testMempoolAcceptResult models an item of the testmempoolaccept response.
+type testMempoolAcceptResult struct {
+	TxID         string `json:"txid"`
+	WTxID        string `json:"wtxid"`
+	PackageError string `json:"package-error"`
+	Allowed      *bool  `json:"allowed"` // Missing if the package failed before the transaction was tested
+	VSize        int64  `json:"vsize"`
+	Fees         *struct {
+		Base float64 `json:"base"` // In BTC
+	} `json:"fees"`
+	RejectReason string `json:"reject-reason"`
+}
+
+// TestPackageAccept tests whether a package of raw transactions, for ex a
+// parent and a child paying for it (CPFP), would be accepted in the mempool
+// together, without broadcasting them. Children must come after their
+// parents.
+//
+// The package fee rate, in sat/vB, is the total fee divided by the total
+// virtual size of the package, and is only set if every transaction would be
+// accepted.
+func (b *Bus) TestPackageAccept(rawTxHexes []string) (*types.PackageAcceptResult, error) {
+	if len(rawTxHexes) == 0 || len(rawTxHexes) > maxPackageCount {
+		return nil, fmt.Errorf("%w: %d transactions, expected 1 to %d",
+			ErrInvalidPackage, len(rawTxHexes), maxPackageCount)
+	}
+
+	rawTxsJSON, err := json.Marshal(rawTxHexes)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("testmempoolaccept", []json.RawMessage{rawTxsJSON})
+	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCDeserialization {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+		}
+
+		return nil, err
+	}
+
+	var results []testMempoolAcceptResult
+	if err := json.Unmarshal(result, &results); err != nil {
+		return nil, fmt.Errorf("unable to parse testmempoolaccept result: %w", err)
+	}
+
+	accept := &types.PackageAcceptResult{
+		Allowed:      true,
+		Transactions: make([]types.PackageTxResult, 0, len(results)),
+	}
+
+	var (
+		totalFee   btcutil.Amount
+		totalVSize int64
+	)
+
+	for _, res := range results {
+		tx := types.PackageTxResult{
+			TxID:         res.TxID,
+			WTxID:        res.WTxID,
+			Allowed:      res.Allowed != nil && *res.Allowed,
+			VSize:        res.VSize,
+			RejectReason: res.RejectReason,
+		}
+
+		if res.Fees != nil {
+			fee := utils.ParseSatoshi(res.Fees.Base)
+			tx.Fee = &fee
+
+			totalFee += fee
+			totalVSize += res.VSize
+		}
+
+		if res.PackageError != "" {
+			accept.PackageError = res.PackageError
+		}
+
+		accept.Allowed = accept.Allowed && tx.Allowed
+		accept.Transactions = append(accept.Transactions, tx)
+	}
+
+	if accept.Allowed && totalVSize > 0 {
+		feeRate := float64(totalFee) / float64(totalVSize)
+		accept.PackageFeeRate = &feeRate
+	}
+
+	return accept, nil
+}
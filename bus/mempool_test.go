@@ -0,0 +1,113 @@
+package bus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestIsOutputSpent(t *testing.T) {
+	// A mempool transaction with a single output.
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(btcutil.SatoshiPerBitcoin, []byte{0x51}))
+
+	var raw bytes.Buffer
+	if err := msgTx.Serialize(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	txid := msgTx.TxHash().String()
+
+	const txOut = `{"bestblock":"0000000000000000000000000000000000000000000000000000000000000001",` +
+		`"confirmations":1,"value":1,"scriptPubKey":{"hex":"51","type":"nonstandard"},"coinbase":false}`
+
+	const notFound = `{"code":-5,"message":"Transaction not in mempool"}`
+
+	tests := []struct {
+		name           string
+		vout           uint32
+		includeMempool bool
+		results        map[string]string
+		errors         map[string]string
+		want           bool
+	}{
+		{
+			name:           "unspent",
+			includeMempool: true,
+			results:        map[string]string{"gettxout": txOut},
+		},
+		{
+			name:           "spent",
+			includeMempool: true,
+			results:        map[string]string{"gettxout": `null`},
+			want:           true,
+		},
+		{
+			// The output is not in the chain UTXO set, and its transaction
+			// is not in the mempool either.
+			name:    "spent in the chain",
+			results: map[string]string{"gettxout": `null`},
+			errors:  map[string]string{"getmempoolentry": notFound},
+			want:    true,
+		},
+		{
+			name: "output of a mempool transaction",
+			results: map[string]string{
+				"gettxout":          `null`,
+				"getmempoolentry":   `{}`,
+				"getrawtransaction": `"` + hex.EncodeToString(raw.Bytes()) + `"`,
+			},
+		},
+		{
+			name: "output index out of range",
+			vout: 1,
+			results: map[string]string{
+				"gettxout":          `null`,
+				"getmempoolentry":   `{}`,
+				"getrawtransaction": `"` + hex.EncodeToString(raw.Bytes()) + `"`,
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, client := newRecordingNode(t, tt.results)
+			for method, rpcErr := range tt.errors {
+				node.errors[method] = rpcErr
+			}
+
+			b := &Bus{mainClient: client}
+
+			spent, err := b.IsOutputSpent(txid, tt.vout, tt.includeMempool)
+			if err != nil {
+				t.Fatalf("IsOutputSpent() error = %v", err)
+			}
+
+			if spent != tt.want {
+				t.Errorf("IsOutputSpent() = %v, want %v", spent, tt.want)
+			}
+
+			calls := node.calls("gettxout")
+			if len(calls) != 1 || len(calls[0].Params) != 3 ||
+				string(calls[0].Params[2]) != strconv.FormatBool(tt.includeMempool) {
+				t.Errorf("gettxout calls = %+v, want include_mempool %v", calls, tt.includeMempool)
+			}
+		})
+	}
+}
+
+func TestIsOutputSpentMalformedHash(t *testing.T) {
+	b := &Bus{}
+
+	if _, err := b.IsOutputSpent("not a txid", 0, true); !errors.Is(err, ErrMalformedChainHash) {
+		t.Fatalf("IsOutputSpent() error = %v, want %v", err, ErrMalformedChainHash)
+	}
+}
@@ -1,8 +1,326 @@
 package bus
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/ledgerhq/satstack/types"
+	"github.com/ledgerhq/satstack/utils"
+)
+
+// behindPeersThreshold is the number of blocks by which the best header known
+// to the peers may exceed the height of the node, before the node is
+// considered to be behind its peers.
+const behindPeersThreshold = 2
+
+// StaleTipAge is the age of the chain tip beyond which the node is likely
+// stalled, or cut off from its peers. Blocks are mined every 10 minutes on
+// average, so the tip is rarely older than that.
+const StaleTipAge = 30 * time.Minute
+
+// TipAge returns the time elapsed since the block time of the chain tip,
+// rounded to the second. Block times may be slightly in the future, in which
+// case the age is 0.
+//
+// Versions of Bitcoin Core older than v23 do not report the time of the tip
+// in getblockchaininfo, ie blockTime is 0, in which case it is read from the
+// header of the best block.
+func TipAge(client *rpcclient.Client, bestBlockHash string, blockTime int64) (time.Duration, error) {
+	if blockTime == 0 {
+		hash, err := chainhash.NewHashFromStr(bestBlockHash)
+		if err != nil {
+			return 0, fmt.Errorf("%s (%s): %w", ErrMalformedChainHash, bestBlockHash, err)
+		}
+
+		header, err := client.GetBlockHeaderVerbose(hash)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
+		}
+
+		blockTime = header.Time
+	}
+
+	age := time.Since(time.Unix(blockTime, 0)).Round(time.Second)
+	if age < 0 {
+		return 0, nil
+	}
+
+	return age, nil
+}
+
+// HealthThresholds defines the conditions under which the node is considered
+// degraded by CheckHealth. Zero values disable the corresponding check.
+type HealthThresholds struct {
+	MaxTipAge       time.Duration // Maximum age of the chain tip
+	MinSyncProgress float64       // Minimum verification progress, in percent
+	MinPeers        int64         // Minimum number of connected peers
+}
+
+// DefaultHealthThresholds are lenient enough not to flag a healthy node,
+// for ex when no block was found for an hour, which happens about once a
+// week.
+var DefaultHealthThresholds = HealthThresholds{
+	MaxTipAge:       90 * time.Minute,
+	MinSyncProgress: 99.9,
+	MinPeers:        1,
+}
+
+// CheckHealth checks the state of the node against the given thresholds, and
+// returns an ErrHealthDegraded error listing all the breached ones, if any.
+func (b *Bus) CheckHealth(thresholds HealthThresholds) error {
+	info, err := b.GetBlockChainInfo()
+	if err != nil {
+		return err
+	}
+
+	var breaches []string
+
+	if thresholds.MaxTipAge > 0 {
+		tipAge, err := TipAge(b.mainClient, info.BestBlockHash, info.Time)
+		if err != nil {
+			return err
+		}
+
+		if tipAge > thresholds.MaxTipAge {
+			breaches = append(breaches, fmt.Sprintf("tip age %s exceeds %s",
+				tipAge, thresholds.MaxTipAge))
+		}
+	}
+
+	if progress := info.VerificationProgress * 100; progress < thresholds.MinSyncProgress {
+		breaches = append(breaches, fmt.Sprintf("sync progress %.2f%% is below %.2f%%",
+			progress, thresholds.MinSyncProgress))
+	}
+
+	if thresholds.MinPeers > 0 {
+		peers, err := b.mainClient.GetConnectionCount()
+		if err != nil {
+			return err
+		}
+
+		if peers < thresholds.MinPeers {
+			breaches = append(breaches, fmt.Sprintf("%d peers, below %d",
+				peers, thresholds.MinPeers))
+		}
+	}
+
+	if len(breaches) > 0 {
+		return fmt.Errorf("%w: %s", ErrHealthDegraded, strings.Join(breaches, ", "))
+	}
+
+	return nil
+}
+
 type Network struct {
 	RelayFee       float64 `json:"relay_fee"`
 	IncrementalFee float64 `json:"incremental_fee"`
 	Version        int32   `json:"version"`
 	Subversion     string  `json:"subversion"`
 }
+
+// GetNodeInfo returns the uptime of the node, along with its number of peer
+// connections.
+//
+// The btcd library does not model the connections_in and connections_out
+// fields of getnetworkinfo, so we use a raw request instead.
+func (b *Bus) GetNodeInfo() (*types.NodeInfo, error) {
+	result, err := b.mainClient.RawRequest("uptime", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var uptime int64
+	if err := json.Unmarshal(result, &uptime); err != nil {
+		return nil, err
+	}
+
+	connections, err := b.mainClient.GetConnectionCount()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = b.mainClient.RawRequest("getnetworkinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var networkInfo struct {
+		NetworkActive  bool  `json:"networkactive"`
+		ConnectionsIn  int64 `json:"connections_in"`
+		ConnectionsOut int64 `json:"connections_out"`
+	}
+
+	if err := json.Unmarshal(result, &networkInfo); err != nil {
+		return nil, err
+	}
+
+	return &types.NodeInfo{
+		Uptime:         uptime,
+		NetworkActive:  networkInfo.NetworkActive,
+		Connections:    connections,
+		ConnectionsIn:  networkInfo.ConnectionsIn,
+		ConnectionsOut: networkInfo.ConnectionsOut,
+	}, nil
+}
+
+// GetChainTips returns the tips of the block tree known by the node, to
+// investigate reorgs or stuck chains.
+func (b *Bus) GetChainTips() ([]types.ChainTip, error) {
+	results, err := b.mainClient.GetChainTips()
+	if err != nil {
+		return nil, err
+	}
+
+	tips := make([]types.ChainTip, 0, len(results))
+	for _, result := range results {
+		tips = append(tips, types.ChainTip{
+			Height:    int64(result.Height),
+			Hash:      result.Hash,
+			BranchLen: int64(result.BranchLen),
+			Status:    result.Status,
+		})
+	}
+
+	return tips, nil
+}
+
+// GetPeers returns the peers connected to the node, with their sync status.
+//
+// The btcd library does not model the synced_headers and synced_blocks fields
+// of getpeerinfo, so we use a raw request instead.
+func (b *Bus) GetPeers() ([]types.PeerInfo, error) {
+	result, err := b.mainClient.RawRequest("getpeerinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []struct {
+		ID             int32   `json:"id"`
+		Addr           string  `json:"addr"`
+		Inbound        bool    `json:"inbound"`
+		Version        uint32  `json:"version"`
+		SubVer         string  `json:"subver"`
+		Services       string  `json:"services"`
+		PingTime       float64 `json:"pingtime"`
+		StartingHeight int32   `json:"startingheight"`
+		SyncedHeaders  int32   `json:"synced_headers"`
+		SyncedBlocks   int32   `json:"synced_blocks"`
+	}
+
+	if err := json.Unmarshal(result, &peers); err != nil {
+		return nil, err
+	}
+
+	peerInfos := make([]types.PeerInfo, len(peers))
+	for idx, peer := range peers {
+		peerInfos[idx] = types.PeerInfo{
+			ID:             peer.ID,
+			Address:        peer.Addr,
+			Inbound:        peer.Inbound,
+			Version:        peer.Version,
+			SubVersion:     peer.SubVer,
+			Services:       peer.Services,
+			PingTime:       peer.PingTime,
+			StartingHeight: peer.StartingHeight,
+			SyncedHeaders:  peer.SyncedHeaders,
+			SyncedBlocks:   peer.SyncedBlocks,
+		}
+	}
+
+	return peerInfos, nil
+}
+
+// IsBehindPeers reports whether the best header height synced with any peer
+// exceeds the given height of the node by more than behindPeersThreshold.
+//
+// This catches nodes that consider themselves synced, while they are
+// actually stalled.
+func (b *Bus) IsBehindPeers(height int64) (bool, error) {
+	peers, err := b.GetPeers()
+	if err != nil {
+		return false, err
+	}
+
+	var best int64
+	for _, peer := range peers {
+		if headers := int64(peer.SyncedHeaders); headers > best {
+			best = headers
+		}
+	}
+
+	return best-height > behindPeersThreshold, nil
+}
+
+// GetNetworkStats returns the current difficulty and estimated hashrate of
+// the network, along with an estimate of the next difficulty adjustment.
+//
+// The estimate is based on the average block interval since the start of the
+// current retarget period. Early in the period, when there are no blocks to
+// average over, the target block interval of the network is used instead.
+func (b *Bus) GetNetworkStats() (*types.NetworkStats, error) {
+	info, err := b.GetBlockChainInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("getnetworkhashps", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashRate float64
+	if err := json.Unmarshal(result, &hashRate); err != nil {
+		return nil, err
+	}
+
+	height := int64(info.Blocks)
+	blocksPerRetarget := int64(b.Params.TargetTimespan / b.Params.TargetTimePerBlock)
+	periodStart := height - height%blocksPerRetarget
+	retargetHeight := periodStart + blocksPerRetarget
+
+	interval := b.Params.TargetTimePerBlock.Seconds()
+	if height > periodStart {
+		startTime, err := b.BlockTime(periodStart)
+		if err != nil {
+			return nil, err
+		}
+
+		tipTime, err := b.BlockTime(height)
+		if err != nil {
+			return nil, err
+		}
+
+		interval = float64(tipTime-startTime) / float64(height-periodStart)
+	}
+
+	remaining := time.Duration(float64(retargetHeight-height)*interval) * time.Second
+
+	return &types.NetworkStats{
+		Height:               height,
+		Difficulty:           info.Difficulty,
+		HashRate:             hashRate,
+		RetargetHeight:       retargetHeight,
+		BlocksUntilRetarget:  retargetHeight - height,
+		AverageBlockInterval: interval,
+		EstimatedRetargetAt:  utils.ParseUnixTimestamp(time.Now().Add(remaining).Unix()),
+	}, nil
+}
+
+// BlockTime returns the header timestamp of the block at the given height.
+func (b *Bus) BlockTime(height int64) (int64, error) {
+	hash, err := b.mainClient.GetBlockHash(height)
+	if err != nil {
+		return 0, err
+	}
+
+	header, err := b.mainClient.GetBlockHeaderVerbose(hash)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.Time, nil
+}
@@ -0,0 +1,160 @@
+package bus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/rpcclient"
+	log "github.com/sirupsen/logrus"
+)
+
+// poolHealthCheckInterval is the interval at which idle clients of the pool
+// are checked, and replaced if unhealthy.
+const poolHealthCheckInterval = time.Minute
+
+// poolAcquireTimeout is how long to wait for a client of the pool to be
+// released, if all of them are in use. Clients are held for the duration of
+// an RPC, so a pool that stays busy that long indicates hung RPCs.
+const poolAcquireTimeout = 10 * time.Second
+
+// clientPool is a fixed-size pool of long-lived RPC clients, to avoid
+// creating and tearing down a client for every service call.
+//
+// A nil entry in the pool stands for a client that must be (re)created on
+// the next acquisition, for ex after a failed health check.
+type clientPool struct {
+	clients        chan *rpcclient.Client
+	factory        func() (*rpcclient.Client, error)
+	acquireTimeout time.Duration
+	done           chan struct{}
+}
+
+// newClientPool creates a pool of size clients, using factory to create them
+// lazily, and starts its health checks.
+func newClientPool(size int, factory func() (*rpcclient.Client, error)) *clientPool {
+	p := &clientPool{
+		clients:        make(chan *rpcclient.Client, size),
+		factory:        factory,
+		acquireTimeout: poolAcquireTimeout,
+		done:           make(chan struct{}),
+	}
+
+	for i := 0; i < size; i++ {
+		p.clients <- nil
+	}
+
+	go p.healthCheck()
+
+	return p
+}
+
+// acquire returns an idle client of the pool, waiting for one to be released
+// if all of them are in use.
+//
+// ErrBitcoindUnreachable is returned if no client is released within the
+// acquire timeout, so that callers do not hang behind stuck RPCs.
+func (p *clientPool) acquire() (*rpcclient.Client, error) {
+	timer := time.NewTimer(p.acquireTimeout)
+	defer timer.Stop()
+
+	var client *rpcclient.Client
+	select {
+	case client = <-p.clients:
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: all %d RPC clients busy for %s",
+			ErrBitcoindUnreachable, cap(p.clients), p.acquireTimeout)
+	}
+
+	if client != nil {
+		return client, nil
+	}
+
+	client, err := p.factory()
+	if err != nil {
+		p.clients <- nil
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// release returns a client acquired from the pool.
+func (p *clientPool) release(client *rpcclient.Client) {
+	p.clients <- client
+}
+
+// healthCheck periodically checks the idle clients of the pool, until the
+// pool is closed.
+func (p *clientPool) healthCheck() {
+	ticker := time.NewTicker(poolHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.checkIdleClients()
+		}
+	}
+}
+
+// checkIdleClients pings the idle clients of the pool, and drops the ones
+// that fail so that they get recreated on the next acquisition. Clients in
+// use are skipped.
+func (p *clientPool) checkIdleClients() {
+	for i := 0; i < cap(p.clients); i++ {
+		var client *rpcclient.Client
+
+		select {
+		case client = <-p.clients:
+		default:
+			return
+		}
+
+		if client != nil {
+			if err := client.Ping(); err != nil {
+				log.WithFields(log.Fields{
+					"prefix": "pool",
+					"error":  err,
+				}).Warn("Dropping unhealthy RPC client")
+
+				client.Shutdown()
+				client = nil
+			}
+		}
+
+		p.clients <- client
+	}
+}
+
+// close stops the health checks, and shuts down the idle clients.
+func (p *clientPool) close() {
+	close(p.done)
+
+	for i := 0; i < cap(p.clients); i++ {
+		select {
+		case client := <-p.clients:
+			if client != nil {
+				client.Shutdown()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// AcquireClient returns a long-lived RPC client from the pool of the Bus.
+// It must be returned with ReleaseClient once done, and must not be shut
+// down by the caller.
+//
+// Use ClientFactory instead for long blocking operations, like importing
+// descriptors, that would otherwise hold a client of the pool.
+func (b *Bus) AcquireClient() (*rpcclient.Client, error) {
+	return b.pool.acquire()
+}
+
+// ReleaseClient returns a client obtained with AcquireClient to the pool.
+func (b *Bus) ReleaseClient(client *rpcclient.Client) {
+	b.pool.release(client)
+}
@@ -0,0 +1,139 @@
+package bus
+
+import (
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+// newStubNode starts an HTTP server answering every JSON-RPC request with
+// the given result, and returns the connection config of a client to it.
+func newStubNode(t testing.TB, result string) *rpcclient.ConnConfig {
+	return startStubNode(t, result, false)
+}
+
+// startStubNode is like newStubNode, optionally serving over TLS.
+func startStubNode(t testing.TB, result string, tls bool) *rpcclient.ConnConfig {
+	return serveStubNode(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"result":`+result+`,"error":null,"id":1}`)
+	}), tls)
+}
+
+// serveStubNode starts a server for the given JSON-RPC handler, and returns
+// the connection config of a client to it.
+func serveStubNode(t testing.TB, handler http.Handler, tls bool) *rpcclient.ConnConfig {
+	server := httptest.NewUnstartedServer(handler)
+	if tls {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+
+	connCfg := &rpcclient.ConnConfig{
+		Host:         strings.TrimPrefix(strings.TrimPrefix(server.URL, "http://"), "https://"),
+		User:         "user",
+		Pass:         "pass",
+		HTTPPostMode: true,
+		DisableTLS:   !tls,
+	}
+
+	if tls {
+		connCfg.Certificates = pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: server.Certificate().Raw,
+		})
+	}
+
+	return connCfg
+}
+
+func TestClientPoolAcquireTimeout(t *testing.T) {
+	connCfg := newStubNode(t, "1")
+
+	pool := newClientPool(1, func() (*rpcclient.Client, error) {
+		return newRPCClient(connCfg, transportOptions{})
+	})
+	defer pool.close()
+
+	pool.acquireTimeout = 10 * time.Millisecond
+
+	client, err := pool.acquire()
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	if _, err := pool.acquire(); !errors.Is(err, ErrBitcoindUnreachable) {
+		t.Fatalf("acquire() on a busy pool error = %v, want %v", err, ErrBitcoindUnreachable)
+	}
+
+	pool.release(client)
+
+	client, err = pool.acquire()
+	if err != nil {
+		t.Fatalf("acquire() after release error = %v", err)
+	}
+
+	pool.release(client)
+}
+
+// BenchmarkClientPerCall measures an RPC made with a client created for the
+// call, as done before the pool was introduced.
+func BenchmarkClientPerCall(b *testing.B) {
+	b.Run("http", func(b *testing.B) { benchmarkClientPerCall(b, false) })
+	b.Run("tls", func(b *testing.B) { benchmarkClientPerCall(b, true) })
+}
+
+func benchmarkClientPerCall(b *testing.B, tls bool) {
+	connCfg := startStubNode(b, "1", tls)
+
+	for i := 0; i < b.N; i++ {
+		client, err := newRPCClient(connCfg, transportOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := client.GetBlockCount(); err != nil {
+			b.Fatal(err)
+		}
+
+		client.Shutdown()
+	}
+}
+
+// BenchmarkPooledClient measures an RPC made with a client of the pool.
+func BenchmarkPooledClient(b *testing.B) {
+	b.Run("http", func(b *testing.B) { benchmarkPooledClient(b, false) })
+	b.Run("tls", func(b *testing.B) { benchmarkPooledClient(b, true) })
+}
+
+func benchmarkPooledClient(b *testing.B, tls bool) {
+	connCfg := startStubNode(b, "1", tls)
+
+	pool := newClientPool(connPoolSize, func() (*rpcclient.Client, error) {
+		return newRPCClient(connCfg, transportOptions{})
+	})
+	defer pool.close()
+
+	for i := 0; i < b.N; i++ {
+		client, err := pool.acquire()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := client.GetBlockCount(); err != nil {
+			b.Fatal(err)
+		}
+
+		pool.release(client)
+	}
+}
@@ -0,0 +1,123 @@
+package bus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ledgerhq/satstack/types"
+	"github.com/ledgerhq/satstack/utils"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// GetTxMerkleProof returns a proof that the given transaction is included in
+// a block, as computed by the gettxoutproof RPC.
+//
+// The proof carries the block header and the partial merkle tree (BIP37), so
+// that clients can verify the inclusion against the header without trusting
+// SatStack. If the transaction is not confirmed, ErrTxUnconfirmed is returned.
+func (b *Bus) GetTxMerkleProof(txid string) (*types.MerkleProof, error) {
+	chainHash, err := utils.ParseChainHash(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHash, err := b.getTxBlockHash(chainHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if blockHash == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTxUnconfirmed, chainHash)
+	}
+
+	txidsJSON, err := json.Marshal([]string{chainHash.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	blockHashJSON, err := json.Marshal(blockHash.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("gettxoutproof", []json.RawMessage{
+		txidsJSON, blockHashJSON,
+	})
+	if err != nil {
+		return nil, b.checkBlockPruned(blockHash, err)
+	}
+
+	var proofHex string
+	if err := json.Unmarshal(result, &proofHex); err != nil {
+		return nil, err
+	}
+
+	proof, err := hex.DecodeString(proofHex)
+	if err != nil {
+		return nil, err
+	}
+
+	var merkleBlock wire.MsgMerkleBlock
+	if err := merkleBlock.BtcDecode(bytes.NewReader(proof),
+		wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	if err := merkleBlock.Header.Serialize(&header); err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(merkleBlock.Hashes))
+	for idx, hash := range merkleBlock.Hashes {
+		hashes[idx] = hash.String()
+	}
+
+	return &types.MerkleProof{
+		TxID:      chainHash.String(),
+		BlockHash: blockHash.String(),
+		Header:    hex.EncodeToString(header.Bytes()),
+		TxCount:   merkleBlock.Transactions,
+		Hashes:    hashes,
+		Flags:     hex.EncodeToString(merkleBlock.Flags),
+		Proof:     proofHex,
+	}, nil
+}
+
+// getTxBlockHash returns the hash of the block containing the given
+// transaction, or nil if the transaction is unconfirmed.
+//
+// Without txindex, only wallet transactions can be located.
+func (b *Bus) getTxBlockHash(txid *chainhash.Hash) (*chainhash.Hash, error) {
+	var blockHash string
+
+	switch b.TxIndex {
+	case true:
+		tx, err := b.mainClient.GetRawTransactionVerbose(txid)
+		if err != nil {
+			return nil, err
+		}
+
+		blockHash = tx.BlockHash
+
+	case false:
+		tx, err := b.mainClient.GetTransactionWatchOnly(txid, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if tx.Confirmations > 0 {
+			blockHash = tx.BlockHash
+		}
+	}
+
+	if blockHash == "" {
+		return nil, nil
+	}
+
+	return chainhash.NewHashFromStr(blockHash)
+}
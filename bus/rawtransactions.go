@@ -2,7 +2,12 @@ package bus
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 
@@ -10,11 +15,15 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func (b *Bus) SendTransaction(tx string) (*chainhash.Hash, error) {
+// SendTransaction broadcasts a serialized transaction to the network.
+//
+// The passed context is only used to correlate the logs with the request
+// that triggered the broadcast.
+func (b *Bus) SendTransaction(ctx context.Context, tx string) (*chainhash.Hash, error) {
 	// Decode the serialized transaction hex to raw bytes.
 	serializedTx, err := hex.DecodeString(tx)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"hex":   tx,
 			"error": err,
 		}).Error("Could not decode transaction hex")
@@ -24,7 +33,7 @@ func (b *Bus) SendTransaction(tx string) (*chainhash.Hash, error) {
 	// Deserialize the transaction and return it.
 	var msgTx wire.MsgTx
 	if err := msgTx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"hex":   tx,
 			"error": err,
 		}).Error("Could not deserialize to wire.MsgTx")
@@ -33,17 +42,81 @@ func (b *Bus) SendTransaction(tx string) (*chainhash.Hash, error) {
 
 	chainHash, err := b.mainClient.SendRawTransaction(&msgTx, true)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"hex":   tx,
 			"error": err,
 		}).Error("sendrawtransaction Bridge failed")
+
+		if strings.Contains(err.Error(), ErrTooLongMempoolChain.Error()) {
+			return nil, b.mempoolChainError(&msgTx, err)
+		}
+
 		return nil, err
 	}
 
-	log.WithFields(log.Fields{
+	log.WithContext(ctx).WithFields(log.Fields{
 		"hex":  tx,
 		"hash": chainHash.String(),
 	}).Info("sendrawtransaction Bridge successful")
 
 	return chainHash, nil
 }
+
+// mempoolLimitRegexp matches the limit in reject reasons of bitcoind, ex:
+// "too-long-mempool-chain, too many unconfirmed ancestors [limit: 25]".
+var mempoolLimitRegexp = regexp.MustCompile(`\[limit: (\d+)\]`)
+
+// MempoolChainError is returned by SendTransaction when a transaction is
+// rejected because it exceeds the mempool ancestor or descendant limits.
+//
+// It carries enough information for clients to advise the user to wait for
+// a confirmation of the unconfirmed parents before retrying.
+type MempoolChainError struct {
+	Reason          string `json:"reason"`           // Reject reason from bitcoind
+	Limit           int    `json:"limit,omitempty"`  // Limit reported by bitcoind, if any
+	AncestorCount   int64  `json:"ancestor_count"`   // Ancestors of the transaction, including itself
+	DescendantCount int64  `json:"descendant_count"` // Descendants of its largest unconfirmed parent, including the transaction
+}
+
+func (e *MempoolChainError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrTooLongMempoolChain, e.Reason)
+}
+
+func (e *MempoolChainError) Unwrap() error {
+	return ErrTooLongMempoolChain
+}
+
+// mempoolChainError builds a MempoolChainError from the reject reason of
+// bitcoind, and the mempool entries of the unconfirmed parents of msgTx.
+//
+// The counts are lower bounds, computed from the unconfirmed parent with the
+// largest number of ancestors (resp. descendants).
+func (b *Bus) mempoolChainError(msgTx *wire.MsgTx, err error) error {
+	chainErr := &MempoolChainError{
+		Reason:          err.Error(),
+		AncestorCount:   1,
+		DescendantCount: 1,
+	}
+
+	if match := mempoolLimitRegexp.FindStringSubmatch(err.Error()); match != nil {
+		chainErr.Limit, _ = strconv.Atoi(match[1])
+	}
+
+	for _, txIn := range msgTx.TxIn {
+		entry, err := b.GetMempoolEntry(txIn.PreviousOutPoint.Hash.String())
+		if err != nil {
+			// Parent transaction is confirmed, or unknown.
+			continue
+		}
+
+		if entry.AncestorCount+1 > chainErr.AncestorCount {
+			chainErr.AncestorCount = entry.AncestorCount + 1
+		}
+
+		if entry.DescendantCount+1 > chainErr.DescendantCount {
+			chainErr.DescendantCount = entry.DescendantCount + 1
+		}
+	}
+
+	return chainErr
+}
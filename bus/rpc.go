@@ -0,0 +1,81 @@
+package bus
+
+import (
+	"encoding/json"
+)
+
+// DefaultRPCAllowlist lists the RPC methods that can be called through the
+// RPC passthrough by default. They only read the state of the node, or
+// perform stateless computations.
+var DefaultRPCAllowlist = []string{
+	// Blockchain
+	"getbestblockhash", "getblock", "getblockchaininfo", "getblockcount",
+	"getblockfilter", "getblockhash", "getblockheader", "getblockstats",
+	"getchaintips", "getchaintxstats", "getdifficulty", "gettxout",
+	"gettxoutproof", "verifytxoutproof",
+
+	// Mempool
+	"getmempoolancestors", "getmempooldescendants", "getmempoolentry",
+	"getmempoolinfo", "getrawmempool", "testmempoolaccept",
+
+	// Network
+	"getconnectioncount", "getnettotals", "getnetworkinfo", "getpeerinfo",
+
+	// Utilities
+	"decodepsbt", "decoderawtransaction", "decodescript", "analyzepsbt",
+	"deriveaddresses", "estimatesmartfee", "getdescriptorinfo",
+	"getindexinfo", "getrawtransaction", "uptime", "validateaddress",
+}
+
+// deniedRPCMethods lists the RPC methods that can never be called through
+// the RPC passthrough, even if allowlisted, since they can stop the node,
+// alter its chain state or mempool, leak or import secrets, spend funds,
+// alter the SatStack wallet, write files on the node, or tie up its
+// resources (including long-polling calls holding a pooled client).
+var deniedRPCMethods = map[string]bool{
+	"stop": true, "invalidateblock": true, "preciousblock": true,
+	"pruneblockchain": true, "setban": true, "clearbanned": true,
+	"setnetworkactive": true, "disconnectnode": true, "addnode": true,
+	"dumpprivkey": true, "dumpwallet": true, "backupwallet": true,
+	"importprivkey": true, "importwallet": true, "importdescriptors": true,
+	"importmulti": true, "importaddress": true, "importpubkey": true,
+	"encryptwallet": true, "walletpassphrase": true,
+	"walletpassphrasechange": true, "sethdseed": true, "createwallet": true,
+	"loadwallet": true, "unloadwallet": true, "restorewallet": true,
+	"migratewallet": true, "rescanblockchain": true, "abortrescan": true,
+	"send": true, "sendall": true, "sendmany": true, "sendtoaddress": true,
+	"sendrawtransaction": true, "submitblock": true, "submitpackage": true,
+	"signmessage": true, "signrawtransactionwithwallet": true,
+	"walletprocesspsbt": true, "settxfee": true, "setlabel": true,
+	"lockunspent": true, "abandontransaction": true, "bumpfee": true,
+	"psbtbumpfee": true, "keypoolrefill": true, "upgradewallet": true,
+	"setwalletflag": true, "logging": true, "walletlock": true,
+	"newkeypool": true, "importprunedfunds": true, "removeprunedfunds": true,
+	"dumptxoutset": true, "loadtxoutset": true, "savemempool": true,
+	"importmempool": true, "scantxoutset": true, "generatetoaddress": true,
+	"generateblock": true, "generatetodescriptor": true,
+	"reconsiderblock": true, "prioritisetransaction": true,
+	"getnewaddress": true, "getrawchangeaddress": true,
+	"addmultisigaddress": true, "walletcreatefundedpsbt": true,
+	"verifychain": true, "waitfornewblock": true, "waitforblock": true,
+	"waitforblockheight": true,
+}
+
+// IsRPCMethodDenied reports whether an RPC method can never be called
+// through the RPC passthrough.
+func IsRPCMethodDenied(method string) bool {
+	return deniedRPCMethods[method]
+}
+
+// CallRPC issues a raw RPC request to the node, on a pooled client. Wallet
+// RPCs are performed on the SatStack wallet.
+func (b *Bus) CallRPC(method string, params []json.RawMessage) (json.RawMessage, error) {
+	client, err := b.AcquireClient()
+	if err != nil {
+		return nil, err
+	}
+
+	defer b.ReleaseClient(client)
+
+	return client.RawRequest(method, params)
+}
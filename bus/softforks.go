@@ -0,0 +1,102 @@
+package bus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/ledgerhq/satstack/types"
+)
+
+// deploymentInfo models a deployment in the softforks field of
+// getblockchaininfo (Bitcoin Core v22), and in the deployments field of
+// getdeploymentinfo (since Bitcoin Core v23).
+type deploymentInfo struct {
+	Type   string `json:"type"` // buried or bip9
+	Active bool   `json:"active"`
+	Height *int64 `json:"height"` // Activation height, if active or buried
+	BIP9   *struct {
+		Status string `json:"status"`
+		Since  int64  `json:"since"`
+	} `json:"bip9"`
+}
+
+// GetSoftForks returns the status of the soft fork deployments of the chain,
+// sorted by name, for ex to check that Taproot is active before deriving tr()
+// addresses.
+//
+// Bitcoin Core v23 moved the deployments from getblockchaininfo to the
+// getdeploymentinfo RPC, so the latter is tried first, and the softforks
+// field of getblockchaininfo is used for Bitcoin Core v22.
+func (b *Bus) GetSoftForks() ([]types.Deployment, error) {
+	result, err := b.mainClient.RawRequest("getdeploymentinfo", nil)
+	if err == nil {
+		var info struct {
+			Deployments map[string]deploymentInfo `json:"deployments"`
+		}
+
+		if err := json.Unmarshal(result, &info); err != nil {
+			return nil, fmt.Errorf("unable to parse deployment info: %w", err)
+		}
+
+		return sortDeployments(deploymentsFromMap(info.Deployments)), nil
+	}
+
+	var rpcErr *btcjson.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != btcjson.ErrRPCMethodNotFound.Code {
+		return nil, err
+	}
+
+	result, err = b.mainClient.RawRequest("getblockchaininfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		SoftForks map[string]deploymentInfo `json:"softforks"`
+	}
+
+	if err := json.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("unable to parse blockchain info: %w", err)
+	}
+
+	return sortDeployments(deploymentsFromMap(info.SoftForks)), nil
+}
+
+// deploymentsFromMap normalizes the deployments keyed by name.
+func deploymentsFromMap(infos map[string]deploymentInfo) []types.Deployment {
+	deployments := make([]types.Deployment, 0, len(infos))
+
+	for name, info := range infos {
+		deployment := types.Deployment{
+			Name:   name,
+			Type:   info.Type,
+			Status: types.DeploymentDefined,
+			Active: info.Active,
+			Height: info.Height,
+		}
+
+		switch {
+		case info.BIP9 != nil:
+			since := info.BIP9.Since
+			deployment.Status = types.DeploymentStatus(info.BIP9.Status)
+			deployment.Since = &since
+		case info.Active:
+			deployment.Status = types.DeploymentActive
+		}
+
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments
+}
+
+func sortDeployments(deployments []types.Deployment) []types.Deployment {
+	sort.Slice(deployments, func(i, j int) bool {
+		return deployments[i].Name < deployments[j].Name
+	})
+
+	return deployments
+}
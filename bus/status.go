@@ -1,5 +1,9 @@
 package bus
 
+import (
+	"time"
+)
+
 // Status indicates the state of LSS with regards to the readiness of the
 // connected Bitcoin Core node.
 type Status string
@@ -14,6 +18,11 @@ const (
 	// endpoint.
 	NodeDisconnected Status = "node-disconnected"
 
+	// AuthFailed is a Status to indicate that the bitcoind instance is
+	// reachable, but rejected the RPC credentials (rpcuser/rpcpassword, or
+	// cookie file).
+	AuthFailed Status = "auth-failed"
+
 	// Ready is a Status to indicate that LSS is ready to accept explorer API
 	// requests from Ledger Live.
 	Ready Status = "ready"
@@ -29,6 +38,17 @@ const (
 	// Use this Status when Bus.IsPendingScan is set to true.
 	PendingScan Status = "pending-scan"
 
+	// WalletLoading is a Status to indicate that the Bitcoin Core node is
+	// reachable, but the SatStack wallet is not loaded yet. This is
+	// typically the case while the wallet is being loaded or created.
+	WalletLoading Status = "wallet-loading"
+
+	// WalletMissing is a Status to indicate that the SatStack wallet does
+	// not exist on the Bitcoin Core node, and cannot be created because of
+	// the nocreatewallet setting. The wallet must be created manually, and
+	// SatStack restarted.
+	WalletMissing Status = "wallet-missing"
+
 	// Scanning is a Status to indicate that the Bitcoin Core node is currently
 	// importing account descriptors into its wallet.
 	Scanning Status = "scanning"
@@ -46,4 +66,64 @@ type ExplorerStatus struct {
 	Status       Status   `json:"status"`
 	SyncProgress *float64 `json:"sync_progress,omitempty"`
 	ScanProgress *float64 `json:"scan_progress,omitempty"`
+	ScanETA      *int64   `json:"scan_eta,omitempty"` // Estimated seconds until the scan completes
+
+	// TipAgeSeconds is the time elapsed since the block time of the chain
+	// tip. A growing value indicates that the node is stalled.
+	TipAgeSeconds *int64 `json:"tip_age_seconds,omitempty"`
+
+	// BehindPeers is set if the peers of the node know of more blocks than
+	// the node itself, which may indicate a stalled node.
+	BehindPeers bool `json:"behind_peers"`
+
+	// DescriptorDrift is set if the wallet descriptors do not match the
+	// configured accounts.
+	DescriptorDrift *DescriptorDrift `json:"descriptor_drift,omitempty"`
+
+	// WalletFormat is the format of the wallet, descriptor or legacy.
+	WalletFormat WalletFormat `json:"wallet_format,omitempty"`
+
+	// Stale is set if the node could not be queried, and the last known good
+	// status is returned instead, within the status grace period.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// minETAProgress is the scan progress, in percent, below which no completion
+// time is estimated, since early estimates are wildly inaccurate.
+const minETAProgress = 1.0
+
+// ScanProgress models the progress of a wallet scan.
+type ScanProgress struct {
+	Progress  float64        // Percentage of blocks scanned
+	Remaining *time.Duration // Estimated time remaining, nil if unknown
+}
+
+// NewScanProgress returns the ScanProgress corresponding to the progress
+// (between 0 and 1) and elapsed time reported by Bitcoin Core.
+//
+// The time remaining is extrapolated from the elapsed time, assuming a
+// constant scan speed.
+func NewScanProgress(progress float64, elapsed time.Duration) *ScanProgress {
+	scan := &ScanProgress{Progress: progress * 100}
+
+	if scan.Progress < minETAProgress || elapsed <= 0 || progress >= 1 {
+		return scan
+	}
+
+	remaining := time.Duration(float64(elapsed) * (1 - progress) / progress).
+		Round(time.Second)
+	scan.Remaining = &remaining
+
+	return scan
+}
+
+// SetScanProgress reports the given scan progress in the status.
+func (s *ExplorerStatus) SetScanProgress(scan *ScanProgress) {
+	progress := scan.Progress
+	s.ScanProgress = &progress
+
+	if scan.Remaining != nil {
+		eta := int64(scan.Remaining.Seconds())
+		s.ScanETA = &eta
+	}
 }
@@ -0,0 +1,150 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/ledgerhq/satstack/types"
+	"github.com/ledgerhq/satstack/utils"
+)
+
+// supplyDustThreshold is the maximum surplus of the actual supply over the
+// expected supply that is tolerated before flagging a mismatch.
+const supplyDustThreshold = btcutil.Amount(546)
+
+// txOutSetInfo models the subset of the gettxoutsetinfo response that is
+// needed for supply checks.
+//
+// The btcd library does not allow passing the hash_type and hash_or_height
+// arguments, so we use a raw request instead.
+type txOutSetInfo struct {
+	Height      int64   `json:"height"`
+	BestBlock   string  `json:"bestblock"`
+	TotalAmount float64 `json:"total_amount"`
+}
+
+// GetBlockSubsidy returns the subsidy of the block at the given height, for
+// the network of the connected node.
+//
+// The subsidy halves every SubsidyReductionInterval blocks (210000 on
+// mainnet, 150 on regtest), and is 0 once it has been halved 64 times.
+func (b *Bus) GetBlockSubsidy(height int64) (btcutil.Amount, error) {
+	if height < 0 || height > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidHeight, height)
+	}
+
+	return btcutil.Amount(blockchain.CalcBlockSubsidy(int32(height), b.Params)), nil
+}
+
+// GetSubsidyInfo returns the subsidy of the block at the given height, along
+// with information about the halving epoch it belongs to.
+func (b *Bus) GetSubsidyInfo(height int64) (*types.SubsidyInfo, error) {
+	subsidy, err := b.GetBlockSubsidy(height)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := int64(b.Params.SubsidyReductionInterval)
+	epoch := height / interval
+	nextHalving := (epoch + 1) * interval
+
+	return &types.SubsidyInfo{
+		Height:             height,
+		Subsidy:            subsidy,
+		Epoch:              epoch,
+		NextHalvingHeight:  nextHalving,
+		BlocksUntilHalving: nextHalving - height,
+	}, nil
+}
+
+// expectedSupply returns the sum of the block subsidies of the blocks 1 to
+// height included, ie the coins that may be in the UTXO set at that height.
+//
+// The coinbase output of the genesis block is not spendable, and is never
+// added to the UTXO set, so its subsidy is left out.
+func (b *Bus) expectedSupply(height int64) btcutil.Amount {
+	interval := int64(b.Params.SubsidyReductionInterval)
+
+	var supply btcutil.Amount
+	for start := int64(0); start <= height; start += interval {
+		subsidy, err := b.GetBlockSubsidy(start)
+		if err != nil || subsidy == 0 {
+			break
+		}
+
+		first, last := start, start+interval-1
+		if first < 1 {
+			first = 1
+		}
+
+		if last > height {
+			last = height
+		}
+
+		if last >= first {
+			supply += subsidy * btcutil.Amount(last-first+1)
+		}
+	}
+
+	return supply
+}
+
+// Supply returns the result of the last circulating supply check performed
+// by the worker, or nil if none completed yet.
+func (b *Bus) Supply() *types.SupplyReport {
+	return b.supply.Load()
+}
+
+// newSupplyReport compares the actual supply at the given height with the
+// expected one.
+//
+// The actual supply is normally lower than the expected supply, since
+// unclaimed block rewards and provably unspendable outputs are not part of
+// the UTXO set. A surplus beyond supplyDustThreshold, however, is flagged as
+// a mismatch.
+func (b *Bus) newSupplyReport(height int64, actual btcutil.Amount) *types.SupplyReport {
+	expected := b.expectedSupply(height)
+	difference := actual - expected
+
+	return &types.SupplyReport{
+		Height:         height,
+		ExpectedSupply: expected,
+		ActualSupply:   actual,
+		Difference:     difference,
+		Mismatch:       difference > supplyDustThreshold,
+	}
+}
+
+// VerifySupplyAtHeight performs a circulating supply check against the UTXO
+// set at a specific historical height.
+//
+// This requires the coinstatsindex to be enabled on the node (enabled by
+// option coinstatsindex=1 in bitcoin.conf).
+func (b *Bus) VerifySupplyAtHeight(height int64) (*types.SupplyReport, error) {
+	hashTypeJSON, err := json.Marshal("none")
+	if err != nil {
+		return nil, err
+	}
+
+	heightJSON, err := json.Marshal(height)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.mainClient.RawRequest("gettxoutsetinfo", []json.RawMessage{
+		hashTypeJSON, heightJSON,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrTxOutSetInfoAtHeight, err)
+	}
+
+	var info txOutSetInfo
+	if err := json.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("unable to parse txoutset info: %w", err)
+	}
+
+	return b.newSupplyReport(info.Height, utils.ParseSatoshi(info.TotalAmount)), nil
+}
@@ -0,0 +1,58 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestSubsidyAndExpectedSupply(t *testing.T) {
+	b := &Bus{Params: &chaincfg.MainNetParams}
+
+	tests := []struct {
+		height  int64
+		subsidy btcutil.Amount
+		supply  btcutil.Amount
+	}{
+		// The subsidy of the genesis block is not spendable.
+		{height: 0, subsidy: 5000000000, supply: 0},
+		{height: 1, subsidy: 5000000000, supply: 5000000000},
+		{height: 209999, subsidy: 5000000000, supply: 1049995000000000},
+		{height: 210000, subsidy: 2500000000, supply: 1049997500000000},
+		{height: 419999, subsidy: 2500000000, supply: 1574995000000000},
+		{height: 420000, subsidy: 1250000000, supply: 1574996250000000},
+
+		// Last epoch with a subsidy, 50 BTC halved 32 times.
+		{height: 6929999, subsidy: 1, supply: 2099994997690000},
+		{height: 6930000, subsidy: 0, supply: 2099994997690000},
+
+		// The subsidy is 0 once halved 64 times.
+		{height: 13440000, subsidy: 0, supply: 2099994997690000},
+	}
+
+	for _, tt := range tests {
+		subsidy, err := b.GetBlockSubsidy(tt.height)
+		if err != nil {
+			t.Fatalf("GetBlockSubsidy(%d) error = %v", tt.height, err)
+		}
+
+		if subsidy != tt.subsidy {
+			t.Errorf("GetBlockSubsidy(%d) = %d, want %d", tt.height, subsidy, tt.subsidy)
+		}
+
+		if supply := b.expectedSupply(tt.height); supply != tt.supply {
+			t.Errorf("expectedSupply(%d) = %d, want %d", tt.height, supply, tt.supply)
+		}
+	}
+}
+
+func TestGetBlockSubsidyInvalidHeight(t *testing.T) {
+	b := &Bus{Params: &chaincfg.MainNetParams}
+
+	for _, height := range []int64{-1, 1 << 32} {
+		if _, err := b.GetBlockSubsidy(height); err == nil {
+			t.Errorf("GetBlockSubsidy(%d) error = nil, want %v", height, ErrInvalidHeight)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package bus
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+// unixSocketScheme is the prefix of RPC URLs that point to a Unix domain
+// socket, ex: unix:///var/run/bitcoind/rpc.sock.
+const unixSocketScheme = "unix://"
+
+// unixSocketPath returns the path of the Unix domain socket specified by
+// rpcURL, and whether rpcURL is a Unix socket URL at all.
+func unixSocketPath(rpcURL string) (string, bool) {
+	if !strings.HasPrefix(rpcURL, unixSocketScheme) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(rpcURL, unixSocketScheme), true
+}
+
+// transportOptions customize the HTTP transport of RPC clients, beyond what
+// rpcclient.ConnConfig supports.
+type transportOptions struct {
+	// socketPath is the path of the Unix domain socket to dial, if the RPC
+	// URL is of the form unix:///path. Empty for TCP connections.
+	socketPath string
+
+	// insecureSkipVerify disables verification of the TLS certificate of the
+	// RPC server.
+	insecureSkipVerify bool
+}
+
+// isDefault reports whether the options leave the rpcclient transport as is.
+func (o transportOptions) isDefault() bool {
+	return o.socketPath == "" && !o.insecureSkipVerify
+}
+
+// newRPCClient creates an rpcclient.Client in HTTP POST mode, with its HTTP
+// transport customized according to opts.
+//
+// Every client gets its own copy of connCfg, since rpcclient caches the
+// credentials read from the cookie file in the config itself.
+func newRPCClient(connCfg *rpcclient.ConnConfig, opts transportOptions) (*rpcclient.Client, error) {
+	cfg := *connCfg
+
+	client, err := rpcclient.New(&cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.isDefault() {
+		return client, nil
+	}
+
+	transport, err := httpTransport(client)
+	if err != nil {
+		client.Shutdown()
+		return nil, err
+	}
+
+	if opts.socketPath != "" {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", opts.socketPath)
+		}
+	}
+
+	if opts.insecureSkipVerify {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+
+	return client, nil
+}
+
+// httpTransport returns the http.Transport used by an rpcclient.Client in
+// HTTP POST mode.
+//
+// rpcclient does not allow customizing its transport, so the unexported
+// HTTP client is looked up through reflection. This must be done before the
+// client issues its first request.
+func httpTransport(client *rpcclient.Client) (*http.Transport, error) {
+	field := reflect.ValueOf(client).Elem().FieldByName("httpClient")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(&http.Client{}) || field.IsNil() {
+		return nil, ErrUnsupportedTransport
+	}
+
+	httpClient := (*http.Client)(field.UnsafePointer())
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, ErrUnsupportedTransport
+	}
+
+	return transport, nil
+}
@@ -1,6 +1,11 @@
 package bus
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/rpcclient"
@@ -11,32 +16,44 @@ import (
 const fallbackFee = btcutil.Amount(1)
 
 func (b *Bus) EstimateSmartFee(target int64, mode string) btcutil.Amount {
-	fee, err := b.mainClient.EstimateSmartFee(target, getMode(mode))
+	fee, err := b.estimateSmartFee(target, mode)
 
 	// If failed to get smart fee estimate, fallback to fallbackFee.
 	// Example: if the full-node is a regtest chain, there are normally
 	// no transactions in the mempool to analyze for estimating fees.
 	//
 	// TODO: Use Minimum Relay Fee instead of btcutil.Amount(1)
+	if err != nil {
+		return fallbackFee
+	}
+
+	return fee
+}
+
+// estimateSmartFee returns the fee estimate of the node for the given
+// confirmation target and mode, or ErrFeeEstimationFailed if the node could
+// not estimate it.
+func (b *Bus) estimateSmartFee(target int64, mode string) (btcutil.Amount, error) {
+	fee, err := b.mainClient.EstimateSmartFee(target, getMode(mode))
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error":  err,
 			"target": target,
 			"mode":   mode,
 		}).Error("Failed estimatesmartfee Bridge")
-		return fallbackFee
+		return 0, err
 	}
 
-	if len(fee.Errors) > 0 {
+	if len(fee.Errors) > 0 || fee.FeeRate == nil {
 		log.WithFields(log.Fields{
 			"error":  fee.Errors,
 			"target": target,
 			"mode":   mode,
 		}).Error("Failed estimatesmartfee Bridge")
-		return fallbackFee
+		return 0, fmt.Errorf("%w: %s", ErrFeeEstimationFailed, strings.Join(fee.Errors, ", "))
 	}
 
-	return utils.ParseSatoshi(*fee.FeeRate)
+	return utils.ParseSatoshi(*fee.FeeRate), nil
 }
 
 func DeriveAddress(client *rpcclient.Client, descriptor string, index int) (*string, error) {
@@ -60,6 +77,28 @@ func DeriveAddress(client *rpcclient.Client, descriptor string, index int) (*str
 	return &(*addresses)[0], nil // *addresses is always a single-element slice
 }
 
+// VerifyDescriptorChecksum checks that the checksum of a descriptor, if one
+// is present, matches the checksum computed by the node. A mismatch usually
+// indicates a transcription error in the descriptor.
+func VerifyDescriptorChecksum(client *rpcclient.Client, descriptor string) error {
+	body, checksum, found := strings.Cut(descriptor, "#")
+	if !found {
+		return nil
+	}
+
+	info, err := client.GetDescriptorInfo(body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrInvalidDescriptor, err)
+	}
+
+	if info.Checksum != checksum {
+		return fmt.Errorf("%w: %s has checksum %s, expected %s",
+			ErrDescriptorChecksum, body, checksum, info.Checksum)
+	}
+
+	return nil
+}
+
 // GetCanonicalDescriptor returns the descriptor in canonical form, along with
 // its computed checksum.
 func GetCanonicalDescriptor(client *rpcclient.Client, descriptor string) (*string, error) {
@@ -70,6 +109,20 @@ func GetCanonicalDescriptor(client *rpcclient.Client, descriptor string) (*strin
 	return &info.Descriptor, nil
 }
 
+// ParseFeeMode normalizes the case of a fee estimation mode, and validates it
+// against the modes supported by estimatesmartfee, plus SmoothedFeeMode. An
+// empty mode defaults to CONSERVATIVE.
+func ParseFeeMode(mode string) (string, error) {
+	switch m := strings.ToUpper(mode); m {
+	case "":
+		return "CONSERVATIVE", nil
+	case "UNSET", "ECONOMICAL", "CONSERVATIVE", SmoothedFeeMode:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%w: '%s'", ErrInvalidFeeMode, mode)
+	}
+}
+
 func getMode(s string) *btcjson.EstimateSmartFeeMode {
 	switch s {
 	case "UNSET":
@@ -82,3 +135,19 @@ func getMode(s string) *btcjson.EstimateSmartFeeMode {
 		return &btcjson.EstimateModeEconomical
 	}
 }
+
+// authErrorRegexp matches the error returned by rpcclient in HTTP POST mode
+// when bitcoind responds with a non JSON-RPC body, and an HTTP 401
+// Unauthorized or 403 Forbidden status.
+var authErrorRegexp = regexp.MustCompile(`status code: (401|403),`)
+
+// IsAuthError reports whether err was caused by bitcoind rejecting the RPC
+// credentials, as opposed to the node being unreachable.
+func IsAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return errors.Is(err, rpcclient.ErrInvalidAuth) ||
+		authErrorRegexp.MatchString(err.Error())
+}
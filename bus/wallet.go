@@ -2,12 +2,15 @@ package bus
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"fmt"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/rpcclient"
 
+	"github.com/ledgerhq/satstack/config"
 	"github.com/ledgerhq/satstack/protocol"
 	"github.com/ledgerhq/satstack/types"
 
@@ -39,6 +42,149 @@ func (b *Bus) ListTransactions(blockHash *string) ([]btcjson.ListTransactionsRes
 	return txs.Transactions, nil
 }
 
+// GetWalletTransactionsSince returns the wallet transactions added in blocks
+// after the given block, or in the mempool, along with the hash of the chain
+// tip to pass in the next call. A nil blockHash returns all transactions.
+//
+// If the given block was reorganized out of the chain, the transactions of
+// the stale blocks are reported in the Removed field, so that clients can
+// invalidate them.
+//
+// The transactions are deduplicated, since listsinceblock returns an entry per
+// wallet output.
+func (b *Bus) GetWalletTransactionsSince(blockHash *chainhash.Hash) (*types.WalletSyncResult, error) {
+	result, err := b.listSinceBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	lastBlock, err := chainhash.NewHashFromStr(result.LastBlock)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%s): %w", ErrMalformedChainHash, result.LastBlock, err)
+	}
+
+	txs, err := b.walletTransactions(result.Transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactions of stale blocks may be re-included in the new chain, in
+	// which case they are not removed.
+	added := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		added[tx.Hash] = true
+	}
+
+	removed := []string{}
+	for _, entry := range result.Removed {
+		if !added[entry.TxID] && !utils.Contains(removed, entry.TxID) {
+			removed = append(removed, entry.TxID)
+		}
+	}
+
+	return &types.WalletSyncResult{
+		Transactions: txs,
+		Removed:      removed,
+		LastBlock:    lastBlock.String(),
+	}, nil
+}
+
+// listSinceBlockResult models the listsinceblock response.
+//
+// The btcd library does not support the include_removed argument, nor the
+// removed field, so we use a raw request instead.
+type listSinceBlockResult struct {
+	Transactions []btcjson.ListTransactionsResult `json:"transactions"`
+	Removed      []btcjson.ListTransactionsResult `json:"removed"`
+	LastBlock    string                           `json:"lastblock"`
+}
+
+func (b *Bus) listSinceBlock(blockHash *chainhash.Hash) (*listSinceBlockResult, error) {
+	// An empty block hash lists all the wallet transactions.
+	blockHashJSON := json.RawMessage(`""`)
+	if blockHash != nil {
+		var err error
+		blockHashJSON, err = json.Marshal(blockHash.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targetConfirmationsJSON, err := json.Marshal(1)
+	if err != nil {
+		return nil, err
+	}
+
+	includeWatchOnlyJSON, err := json.Marshal(true)
+	if err != nil {
+		return nil, err
+	}
+
+	includeRemovedJSON, err := json.Marshal(true)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := b.mainClient.RawRequest("listsinceblock", []json.RawMessage{
+		blockHashJSON, targetConfirmationsJSON, includeWatchOnlyJSON, includeRemovedJSON,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result listSinceBlockResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// walletTransactions decodes the transactions of the given wallet entries,
+// once per transaction ID, along with the block they were included in.
+func (b *Bus) walletTransactions(entries []btcjson.ListTransactionsResult) ([]types.Transaction, error) {
+	txs := make([]types.Transaction, 0, len(entries))
+	visited := make(map[string]bool, len(entries))
+
+	for _, entry := range entries {
+		if visited[entry.TxID] {
+			continue
+		}
+
+		visited[entry.TxID] = true
+
+		tx, err := b.GetTransaction(entry.TxID)
+		if err != nil {
+			return nil, err
+		}
+
+		height := int64(-1)
+		if entry.BlockHeight != nil && entry.Confirmations > 0 {
+			height = int64(*entry.BlockHeight)
+		}
+
+		// Copy the transaction, since it may be shared with the Bus cache.
+		walletTx := *tx
+		walletTx.Block = &types.Block{
+			Hash:   entry.BlockHash,
+			Height: height,
+			Time:   utils.ParseUnixTimestamp(entry.BlockTime),
+		}
+
+		// The wallet reports negative confirmations for transactions
+		// conflicting with the active chain. Other confirmations are
+		// computed by the caller, against its own tip snapshot.
+		walletTx.Confirmations = 0
+		if entry.Confirmations < 0 {
+			walletTx.Confirmations = types.OrphanedConfirmations
+		}
+
+		txs = append(txs, walletTx)
+	}
+
+	return txs, nil
+}
+
 func (b *Bus) GetTransactionHex(hash *chainhash.Hash) (string, error) {
 	tx, err := b.mainClient.GetTransactionWatchOnly(hash, true)
 	if err != nil {
@@ -48,6 +194,161 @@ func (b *Bus) GetTransactionHex(hash *chainhash.Hash) (string, error) {
 	return tx.Hex, nil
 }
 
+// GetRawTransaction returns the serialized transaction with the given ID,
+// hex-encoded, so that clients can rebroadcast or verify it. Unlike
+// GetTransactionHex, the transaction needs not belong to the wallet.
+//
+// Without a transaction index, only mempool transactions can be found, and
+// ErrTxIndexRequired is returned for the others.
+func (b *Bus) GetRawTransaction(txid string) (string, error) {
+	txidJSON, err := json.Marshal(txid)
+	if err != nil {
+		return "", err
+	}
+
+	verboseJSON, err := json.Marshal(false)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := b.mainClient.RawRequest("getrawtransaction",
+		[]json.RawMessage{txidJSON, verboseJSON})
+	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if !b.TxIndex && errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCNoTxInfo {
+			return "", fmt.Errorf("%w: %s", ErrTxIndexRequired, txid)
+		}
+
+		return "", err
+	}
+
+	var txHex string
+	if err := json.Unmarshal(result, &txHex); err != nil {
+		return "", err
+	}
+
+	return txHex, nil
+}
+
+// maxConfirmations is the upper bound on the number of confirmations used
+// when listing UTXOs, effectively meaning "no upper bound".
+const maxConfirmations = 9999999
+
+// ListUnspent returns the wallet UTXOs belonging to the given addresses, with
+// at least minConf confirmations. A minConf of 0 includes unconfirmed UTXOs.
+//
+// Immature coinbase outputs are never returned by bitcoind, regardless of
+// minConf.
+//
+// An empty list of addresses returns no UTXOs, since bitcoind would
+// otherwise return all the UTXOs of the wallet.
+func (b *Bus) ListUnspent(addresses []string, minConf int) ([]types.UnspentOutput, error) {
+	if len(addresses) == 0 {
+		return []types.UnspentOutput{}, nil
+	}
+
+	addrs := make([]btcutil.Address, 0, len(addresses))
+	for _, address := range addresses {
+		addr, err := btcutil.DecodeAddress(address, b.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s): %w", ErrInvalidAddress, address, err)
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	results, err := b.mainClient.ListUnspentMinMaxAddresses(
+		minConf, maxConfirmations, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]types.UnspentOutput, 0, len(results))
+	for _, result := range results {
+		utxos = append(utxos, types.UnspentOutput{
+			OutputHash:    result.TxID,
+			OutputIndex:   result.Vout,
+			Address:       result.Address,
+			Value:         utils.ParseSatoshi(result.Amount),
+			ScriptHex:     result.ScriptPubKey,
+			Confirmations: result.Confirmations,
+		})
+	}
+
+	return utxos, nil
+}
+
+// maxBalancesBatchSize is the maximum number of addresses whose balances can
+// be requested at once with GetBalances.
+const maxBalancesBatchSize = 1000
+
+// GetBalances returns the balance of each of the given addresses, computed
+// from the wallet UTXOs with at least minConf confirmations. Addresses
+// without UTXOs have a zero balance.
+//
+// Unlike calling GetBalance for each address, the UTXOs are listed in a
+// single listunspent call, and grouped by address client-side.
+func (b *Bus) GetBalances(addresses []string, minConf int) (map[string]types.Balance, error) {
+	if len(addresses) > maxBalancesBatchSize {
+		return nil, fmt.Errorf("%w: %d addresses, at most %d allowed",
+			ErrBatchTooLarge, len(addresses), maxBalancesBatchSize)
+	}
+
+	balances := make(map[string]types.Balance, len(addresses))
+	for _, address := range addresses {
+		if _, err := btcutil.DecodeAddress(address, b.Params); err != nil {
+			return nil, fmt.Errorf("%w (%s): %v", ErrInvalidAddress, address, err)
+		}
+
+		balances[address] = types.Balance{}
+	}
+
+	results, err := b.mainClient.ListUnspentMinMax(minConf, maxConfirmations)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		balance, ok := balances[result.Address]
+		if !ok {
+			continue
+		}
+
+		if result.Confirmations > 0 {
+			balance.Confirmed += utils.ParseSatoshi(result.Amount)
+		} else {
+			balance.Unconfirmed += utils.ParseSatoshi(result.Amount)
+		}
+
+		balance.UTXOCount++
+		balances[result.Address] = balance
+	}
+
+	return balances, nil
+}
+
+// GetBalance returns the aggregate balance of the wallet UTXOs belonging to
+// the given addresses, with at least minConf confirmations.
+func (b *Bus) GetBalance(addresses []string, minConf int) (*types.Balance, error) {
+	utxos, err := b.ListUnspent(addresses, minConf)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance types.Balance
+	for _, utxo := range utxos {
+		if utxo.Confirmations > 0 {
+			balance.Confirmed += utxo.Value
+		} else {
+			balance.Unconfirmed += utxo.Value
+		}
+	}
+
+	balance.UTXOCount = len(utxos)
+
+	return &balance, nil
+}
+
 type RescanResult struct {
 	StartHeight uint32 `json:"start_height"`
 	StopHeight  uint32 `json:"stop_height"`
@@ -55,17 +356,18 @@ type RescanResult struct {
 
 // see https://developer.bitcoin.org/reference/rpc/importdescriptors.html for specs
 type ImportDesciptorRequest struct {
-	Descriptor string `json:"desc"`                 //(string, required) Descriptor to import.
-	Active     bool   `json:"active,omitempty"`     //(boolean, optional, default=false) Set this descriptor to be the active descriptor for the corresponding output type/externality
-	Range      []int  `json:"range,omitempty"`      //(numeric or array) If a ranged descriptor is used, this specifies the end or the range (in the form [begin,end]) to import
-	NextIndex  int    `json:"next_index,omitempty"` //(numeric) If a ranged descriptor is set to active, this specifies the next index to generate addresses from
-	Timestamp  uint32 `json:"timestamp"`            /*(integer / string, required) Time from which to start rescanning the blockchain for this descriptor, in UNIX epoch time
+	Descriptor string      `json:"desc"`                 //(string, required) Descriptor to import.
+	Active     bool        `json:"active,omitempty"`     //(boolean, optional, default=false) Set this descriptor to be the active descriptor for the corresponding output type/externality
+	Range      []int       `json:"range,omitempty"`      //(numeric or array) If a ranged descriptor is used, this specifies the end or the range (in the form [begin,end]) to import
+	NextIndex  int         `json:"next_index,omitempty"` //(numeric) If a ranged descriptor is set to active, this specifies the next index to generate addresses from
+	Timestamp  interface{} `json:"timestamp"`            /*(integer / string, required) Time from which to start rescanning the blockchain for this descriptor, in UNIX epoch time
 	Use the string "now" to substitute the current synced blockchain time.
 	"now" can be specified to bypass scanning, for outputs which are known to never have been used, and
 	0 can be specified to scan the entire blockchain. Blocks up to 2 hours before the earliest timestamp
 	of all descriptors being imported will be scanned.*/
 	Internal bool `json:"internal,omitempty"` //(boolean, optional, default=false) Whether matching outputs should be treated as not incoming payments (e.g. change)
 	// Label    string `json:"label",omitempty`    //(string, optional, default='') Label to assign to the address, only allowed with internal=false
+	// Ranged descriptors cannot have a label, see labelAddresses.
 }
 
 type ImportDescriptorResult struct {
@@ -74,9 +376,26 @@ type ImportDescriptorResult struct {
 	Error    btcjson.RPCError `json:"error"`
 }
 
-func ImportDescriptors(client *rpcclient.Client, descriptors []descriptor) error {
+// fullRescanTimestamp is the importdescriptors timestamp that makes Bitcoin
+// Core scan the entire blockchain, from the genesis block.
+const fullRescanTimestamp uint32 = 0
+
+// ImportDescriptors imports the given descriptors into the wallet in a single
+// importdescriptors call. Legacy wallets, as indicated by the format, do not
+// support importdescriptors, so importmulti is used for them instead.
+//
+// If rescan is false, the descriptors are imported with the timestamp "now",
+// which skips scanning historical blocks. Otherwise, the age of each
+// descriptor is used as timestamp, where an age of 0 (fullRescanTimestamp)
+// triggers a rescan of the entire blockchain.
+func ImportDescriptors(client *rpcclient.Client, descriptors []descriptor, rescan bool, format WalletFormat) error {
+	if format == LegacyWallet {
+		return importMulti(client, descriptors, rescan)
+	}
 
-	// We are going to import all descriptors together which saves us a lot of time
+	// We are going to import all descriptors together which saves us a lot of time,
+	// since Core then performs a single rescan for all of them, from the
+	// earliest timestamp, instead of one rescan per descriptor.
 
 	var requestDescriptors []ImportDesciptorRequest
 	var params []json.RawMessage
@@ -90,6 +409,10 @@ func ImportDescriptors(client *rpcclient.Client, descriptors []descriptor) error
 			Timestamp:  descriptor.Age,
 		}
 
+		if !rescan {
+			requests.Timestamp = "now"
+		}
+
 		requestDescriptors = append(requestDescriptors, requests)
 
 	}
@@ -121,26 +444,255 @@ func ImportDescriptors(client *rpcclient.Client, descriptors []descriptor) error
 		return umerr
 	}
 
+	if len(importDescriptorResult) != len(requestDescriptors) {
+		return fmt.Errorf("ImportDescriptors - expected %d results, got %d",
+			len(requestDescriptors), len(importDescriptorResult))
+	}
+
 	var hasError bool
 
 	fields := log.WithFields(log.Fields{
 		"NumofDescriptors": len(requestDescriptors),
 	})
 
-	if !importDescriptorResult[0].Success {
-
-		fields.Error("ImportDescriptors - Failed to import descriptor" + " || " + importDescriptorResult[0].Error.Error())
-		hasError = true
-	} else {
-		fields.Debug("ImportDescriptors - Import descriptor successfully")
+	// Core returns one result per descriptor, in the order of the request.
+	for i, res := range importDescriptorResult {
+		if !res.Success {
+			fields.WithField(
+				"descriptor", requestDescriptors[i].Descriptor,
+			).Error("ImportDescriptors - Failed to import descriptor" + " || " + res.Error.Error())
+			hasError = true
+		}
 	}
 
 	if hasError {
 		return fmt.Errorf("ImportDescriptors - importdescriptor RPC failed")
 	}
 
+	fields.Debug("ImportDescriptors - Import descriptor successfully")
+
+	return labelAddresses(client, descriptors)
+
+}
+
+// WalletFormat is the storage format of a Bitcoin Core wallet, which
+// determines the RPC used to import descriptors.
+type WalletFormat string
+
+const (
+	// DescriptorWallet wallets import descriptors with importdescriptors.
+	DescriptorWallet WalletFormat = "descriptor"
+
+	// LegacyWallet wallets import descriptors with importmulti. They are
+	// deprecated, and no longer supported by recent Bitcoin Core versions.
+	LegacyWallet WalletFormat = "legacy"
+)
+
+// detectWalletFormat returns the format of the wallet. The descriptors field
+// of getwalletinfo is missing on nodes that predate descriptor wallets, in
+// which case the wallet is a legacy one.
+func detectWalletFormat(client *rpcclient.Client) (WalletFormat, error) {
+	result, err := client.RawRequest("getwalletinfo", nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to get wallet info: %w", err)
+	}
+
+	var info struct {
+		Descriptors bool `json:"descriptors"`
+	}
+
+	if err := json.Unmarshal(result, &info); err != nil {
+		return "", fmt.Errorf("unable to parse wallet info: %w", err)
+	}
+
+	if !info.Descriptors {
+		return LegacyWallet, nil
+	}
+
+	return DescriptorWallet, nil
+}
+
+// importMultiRequest models a descriptor to import with importmulti.
+//
+// See https://developer.bitcoin.org/reference/rpc/importmulti.html for specs.
+type importMultiRequest struct {
+	Descriptor string      `json:"desc"`
+	Range      []int       `json:"range,omitempty"`
+	Timestamp  interface{} `json:"timestamp"` // Same as in ImportDesciptorRequest
+	WatchOnly  bool        `json:"watchonly"`
+}
+
+// importMulti is the equivalent of ImportDescriptors for legacy wallets, which
+// imports the descriptors as watch-only with a single importmulti call.
+func importMulti(client *rpcclient.Client, descriptors []descriptor, rescan bool) error {
+	requests := make([]importMultiRequest, 0, len(descriptors))
+
+	for _, descriptor := range descriptors {
+		request := importMultiRequest{
+			Descriptor: descriptor.Value,
+			Range:      []int{0, descriptor.Depth},
+			Timestamp:  descriptor.Age,
+			WatchOnly:  true,
+		}
+
+		if !rescan {
+			request.Timestamp = "now"
+		}
+
+		requests = append(requests, request)
+	}
+
+	requestsJSON, err := json.Marshal(requests)
+	if err != nil {
+		return err
+	}
+
+	optionsJSON, err := json.Marshal(map[string]bool{"rescan": rescan})
+	if err != nil {
+		return err
+	}
+
+	fields := log.WithFields(log.Fields{
+		"prefix":      "worker",
+		"descriptors": len(requests),
+	})
+
+	fields.Info("Importing descriptors into legacy wallet with importmulti")
+
+	result, err := client.RawRequest("importmulti", []json.RawMessage{requestsJSON, optionsJSON})
+	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCMethodNotFound.Code {
+			return ErrImportUnsupported
+		}
+
+		return err
+	}
+
+	var results []ImportDescriptorResult
+	if err := json.Unmarshal(result, &results); err != nil {
+		return fmt.Errorf("unable to parse importmulti result: %w", err)
+	}
+
+	if len(results) != len(requests) {
+		return fmt.Errorf("importmulti - expected %d results, got %d",
+			len(requests), len(results))
+	}
+
+	var hasError bool
+
+	// Core returns one result per request, in the order of the request.
+	for i, res := range results {
+		if !res.Success {
+			fields.WithFields(log.Fields{
+				"descriptor": requests[i].Descriptor,
+				"error":      res.Error.Error(),
+			}).Error("Failed to import descriptor with importmulti")
+			hasError = true
+		}
+	}
+
+	if hasError {
+		return fmt.Errorf("importmulti RPC failed")
+	}
+
+	fields.Debug("Imported descriptors with importmulti")
+
+	return labelAddresses(client, descriptors)
+}
+
+// labelAddresses assigns the label of each descriptor, if any, to all the
+// addresses derived from it.
+//
+// Bitcoin Core rejects labels on ranged descriptors in importdescriptors, so
+// the label is set on each address of the imported range instead. Addresses
+// already holding the label, from a previous import, are skipped so that
+// reimports only label the new part of the range.
+func labelAddresses(client *rpcclient.Client, descriptors []descriptor) error {
+	for _, descriptor := range descriptors {
+		if descriptor.Label == "" {
+			continue
+		}
+
+		addresses, err := client.DeriveAddresses(
+			descriptor.Value,
+			&btcjson.DescriptorRange{Value: []int{0, descriptor.Depth}},
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ErrInvalidDescriptor, err)
+		}
+
+		labelled, err := labelledAddresses(client, descriptor.Label)
+		if err != nil {
+			return err
+		}
+
+		label, err := json.Marshal(descriptor.Label)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		for _, address := range *addresses {
+			if labelled[address] {
+				continue
+			}
+
+			addr, err := json.Marshal(address)
+			if err != nil {
+				return err
+			}
+
+			if _, err := client.RawRequest("setlabel", []json.RawMessage{addr, label}); err != nil {
+				return fmt.Errorf("failed to set label of address %s: %w", address, err)
+			}
+
+			count++
+		}
+
+		log.WithFields(log.Fields{
+			"prefix":    "worker",
+			"label":     descriptor.Label,
+			"addresses": count,
+			"skipped":   len(*addresses) - count,
+		}).Info("Labelled imported addresses")
+	}
+
 	return nil
+}
+
+// labelledAddresses returns the set of wallet addresses holding the given
+// label.
+func labelledAddresses(client *rpcclient.Client, label string) (map[string]bool, error) {
+	labelJSON, err := json.Marshal(label)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.RawRequest("getaddressesbylabel", []json.RawMessage{labelJSON})
+	if err != nil {
+		// Bitcoin Core reports an unknown label with the code of
+		// RPC_WALLET_INVALID_LABEL_NAME, named after accounts in btcjson.
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCWalletInvalidAccountName {
+			return map[string]bool{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to get addresses of label %s: %w", label, err)
+	}
 
+	// The result is keyed by address, with the purpose of each one.
+	var addresses map[string]json.RawMessage
+	if err := json.Unmarshal(result, &addresses); err != nil {
+		return nil, err
+	}
+
+	labelled := make(map[string]bool, len(addresses))
+	for address := range addresses {
+		labelled[address] = true
+	}
+
+	return labelled, nil
 }
 
 func (b *Bus) GetTransaction(hash string) (*types.Transaction, error) {
@@ -220,7 +772,99 @@ func (b *Bus) checkWalletSyncStatus() error {
 
 // Triggers the bitcoind api to rescan the wallet, in case the wallet
 // satstack already existed
+//
+// If a rescan chunk size is configured, the range is rescanned in windows of
+// that many blocks. Progress is persisted to the rescan checkpoint file after
+// each window, and the rescan stops with ErrRescanAborted between windows if
+// AbortRescan was called.
 func (b *Bus) rescanWallet(startHeight int64, endHeight int64) error {
+	chunkSize := b.rescanChunkSize
+	if chunkSize <= 0 {
+		chunkSize = endHeight - startHeight + 1
+	}
+
+	b.rescanning.Store(true)
+	defer b.rescanning.Store(false)
+
+	b.rescanAborted.Store(false)
+
+	// The checkpoint only covers the descriptors imported before the rescan
+	// began, which is recorded with the median time past of the tip, like
+	// the timestamps of descriptors imported without a rescan.
+	startTime, err := b.getMedianTime(endHeight)
+	if err != nil {
+		return err
+	}
+
+	// A rescan resuming from the checkpoint extends the range rescanned by
+	// the previous one, unless descriptors were imported since the previous
+	// one began. Otherwise, the rescanned range only covers the descriptors
+	// once the rescan completes.
+	rescanStart := startHeight
+	var rescanTime *int64
+	if previous, err := config.LoadRescanConf(); err == nil && previous.RescanStart != nil &&
+		previous.RescanTime != nil && *previous.RescanStart < startHeight &&
+		startHeight <= previous.LastBlock+1 && !b.importedSince(*previous.RescanTime) {
+		rescanStart = *previous.RescanStart
+		rescanTime = previous.RescanTime
+	}
+
+	for from := startHeight; from <= endHeight; from += chunkSize {
+		to := from + chunkSize - 1
+		if to > endHeight {
+			to = endHeight
+		}
+
+		if err := b.rescanBlocks(from, to); err != nil {
+			// The rescanblockchain call was interrupted by AbortRescan.
+			if b.rescanAborted.Load() {
+				return fmt.Errorf("%w: %v", ErrRescanAborted, err)
+			}
+
+			return err
+		}
+
+		if to == endHeight && rescanTime == nil {
+			rescanTime = &startTime
+		}
+
+		if err := b.dumpRescanCheckpoint(&rescanStart, rescanTime, to); err != nil {
+			return err
+		}
+
+		if b.rescanAborted.Load() && to < endHeight {
+			log.WithFields(log.Fields{
+				"prefix":     "RescanWallet",
+				"lastHeight": to,
+				"endHeight":  endHeight,
+			}).Warn("Rescan aborted")
+
+			return ErrRescanAborted
+		}
+	}
+
+	return nil
+}
+
+// importedSince reports whether a descriptor of the wallet was imported after
+// the given median time past, or if this is unknown.
+func (b *Bus) importedSince(medianTime int64) bool {
+	imported, err := b.listDescriptors()
+	if err != nil {
+		return true
+	}
+
+	for _, desc := range imported.Descriptors {
+		if int64(desc.Timestamp) > medianTime {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rescanBlocks performs a rescanblockchain call on the given block range.
+func (b *Bus) rescanBlocks(startHeight int64, endHeight int64) error {
 
 	client, err := b.ClientFactory()
 	if err != nil {
@@ -286,17 +930,32 @@ func (b *Bus) rescanWallet(startHeight int64, endHeight int64) error {
 
 }
 
-func (b *Bus) AbortRescan() error {
+// AbortRescan stops the wallet rescan currently in progress, if any. It
+// reports whether a rescan was actually aborted, so that calling it while no
+// rescan is running is a no-op.
+//
+// The wallet is only reported as no longer pending a scan if bitcoind
+// aborted a rescan, since other operations, such as the circulation check,
+// also keep it pending.
+func (b *Bus) AbortRescan() (bool, error) {
 
 	var params []json.RawMessage
 	var abortRescan bool
 
-	client, err := b.ClientFactory()
+	// Stop chunked rescans at the end of the current window. This is set
+	// before calling abortrescan, since bitcoind has nothing to abort
+	// between two windows.
+	chunkedRescan := b.rescanning.Load()
+	if chunkedRescan {
+		b.rescanAborted.Store(true)
+	}
+
+	client, err := b.AcquireClient()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	defer client.Shutdown()
+	defer b.ReleaseClient(client)
 
 	result, err := client.RawRequest("abortrescan", params)
 
@@ -306,22 +965,107 @@ func (b *Bus) AbortRescan() error {
 			"error":  err,
 		}).Error("Failed to abort wallet rescan")
 
-		return err
+		return false, err
 	}
 
 	umerr := json.Unmarshal(result, &abortRescan)
 
 	if umerr != nil {
 		log.Error(`umerr`, umerr)
-		return umerr
+		return false, umerr
 	}
 
 	log.WithFields(log.Fields{
 		"prefix": "AbortRescan",
 	}).Infof("Abort rescan successful: %t", abortRescan)
 
-	b.IsPendingScan = false
+	if abortRescan {
+		b.IsPendingScan = false
+	}
 
-	return nil
+	return abortRescan || chunkedRescan, nil
+
+}
+
+// LoadWalletAsync loads the SatStack wallet in the background, creating it
+// if it does not exist. Calling it while a load is in progress, or once the
+// wallet was found to be missing, is a no-op.
+//
+// Use IsWalletLoading to check whether the load has completed.
+func (b *Bus) LoadWalletAsync() {
+	if b.walletMissing.Load() || !b.walletLoading.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer b.walletLoading.Store(false)
+
+		client, err := b.ClientFactory()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"prefix": "LoadWallet",
+				"error":  err,
+			}).Error("Failed to load wallet")
+			return
+		}
+
+		defer client.Shutdown()
+
+		created, err := loadOrCreateWallet(client, b.WalletName, b.createWallet)
+		if err != nil {
+			if errors.Is(err, ErrWalletNotFound) {
+				b.walletMissing.Store(true)
+			}
+
+			log.WithFields(log.Fields{
+				"prefix": "LoadWallet",
+				"wallet": b.WalletName,
+				"error":  err,
+			}).Error("Failed to load wallet")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"prefix":  "LoadWallet",
+			"wallet":  b.WalletName,
+			"created": created,
+		}).Info("Wallet loaded")
+	}()
+}
+
+// IsWalletLoading reports whether the wallet is being loaded in the
+// background.
+func (b *Bus) IsWalletLoading() bool {
+	return b.walletLoading.Load()
+}
+
+// IsWalletMissing reports whether LoadWalletAsync found that the wallet
+// does not exist, while wallet creation is disabled with nocreatewallet.
+func (b *Bus) IsWalletMissing() bool {
+	return b.walletMissing.Load()
+}
+
+// RescanCheckpoint models the last block that the wallet was successfully
+// rescanned up to.
+type RescanCheckpoint struct {
+	Rescanned bool   `json:"rescanned"`            // false if the wallet was never rescanned
+	Height    *int64 `json:"height,omitempty"`     // Height of the last rescanned block
+	BlockTime *int64 `json:"block_time,omitempty"` // Header timestamp of the last rescanned block
+}
+
+// GetRescanCheckpoint returns the height of the last block that the wallet
+// was successfully rescanned up to, as recorded in lss_rescan.json.
+//
+// ErrNeverRescanned is returned if no checkpoint exists yet.
+func (b *Bus) GetRescanCheckpoint() (int64, error) {
+	checkpoint, err := config.LoadRescanConf()
+	if errors.Is(err, config.ErrConfigFileNotFound) {
+		return 0, ErrNeverRescanned
+	}
+
+	if err != nil {
+		return 0, err
+	}
 
+	return checkpoint.LastBlock, nil
 }
@@ -0,0 +1,260 @@
+package bus
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+// rpcRequest is a JSON-RPC request received by a recordingNode.
+type rpcRequest struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	ID     json.RawMessage   `json:"id"`
+}
+
+// recordingNode is a stub node recording the JSON-RPC requests it receives,
+// and answering each of them with the result or the error configured for
+// its method.
+type recordingNode struct {
+	connCfg *rpcclient.ConnConfig
+
+	mu       sync.Mutex
+	requests []rpcRequest
+	results  map[string]string
+	errors   map[string]string
+}
+
+// newRecordingNode starts a recordingNode answering with the given results,
+// keyed by method, and returns a client to it.
+func newRecordingNode(t *testing.T, results map[string]string) (*recordingNode, *rpcclient.Client) {
+	node := &recordingNode{results: results, errors: map[string]string{}}
+
+	node.connCfg = serveStubNode(t, http.HandlerFunc(node.serveHTTP), false)
+
+	client, err := newRPCClient(node.connCfg, transportOptions{})
+	if err != nil {
+		t.Fatalf("newRPCClient() error = %v", err)
+	}
+	t.Cleanup(client.Shutdown)
+
+	return node, client
+}
+
+func (n *recordingNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var request rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n.mu.Lock()
+	n.requests = append(n.requests, request)
+	result, ok := n.results[request.Method]
+	rpcErr, failed := n.errors[request.Method]
+	n.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if failed {
+		_, _ = w.Write([]byte(`{"result":null,"error":` + rpcErr + `,"id":` + string(request.ID) + `}`))
+		return
+	}
+
+	if !ok {
+		_, _ = w.Write([]byte(`{"result":null,"error":{"code":-32601,"message":"Method not found"},"id":` +
+			string(request.ID) + `}`))
+		return
+	}
+
+	_, _ = w.Write([]byte(`{"result":` + result + `,"error":null,"id":` + string(request.ID) + `}`))
+}
+
+// setResult changes the result of the given method.
+func (n *recordingNode) setResult(method string, result string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.results[method] = result
+}
+
+// calls returns the requests received for the given method.
+func (n *recordingNode) calls(method string) []rpcRequest {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var calls []rpcRequest
+	for _, request := range n.requests {
+		if request.Method == method {
+			calls = append(calls, request)
+		}
+	}
+
+	return calls
+}
+
+func TestImportDescriptorsSingleCall(t *testing.T) {
+	descriptors := []descriptor{
+		{Value: "wpkh([8a2b9c1d/84'/1'/0']tpubA/0/*)#aaaaaaaa", Depth: 100, Age: 1600000000},
+		{Value: "wpkh([8a2b9c1d/84'/1'/0']tpubA/1/*)#bbbbbbbb", Depth: 100, Age: 1600000000},
+		{Value: "wpkh([3c4d5e6f/84'/1'/0']tpubB/0/*)#cccccccc", Depth: 50, Age: 1650000000},
+	}
+
+	tests := []struct {
+		name      string
+		rescan    bool
+		timestamp func(descriptor) interface{}
+	}{
+		{
+			name:      "rescan",
+			rescan:    true,
+			timestamp: func(d descriptor) interface{} { return float64(d.Age) },
+		},
+		{
+			name:      "no rescan",
+			rescan:    false,
+			timestamp: func(descriptor) interface{} { return "now" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, client := newRecordingNode(t, map[string]string{
+				"importdescriptors": `[{"success":true},{"success":true},{"success":true}]`,
+			})
+
+			if err := ImportDescriptors(client, descriptors, tt.rescan, DescriptorWallet); err != nil {
+				t.Fatalf("ImportDescriptors() error = %v", err)
+			}
+
+			if len(node.requests) != 1 {
+				t.Fatalf("ImportDescriptors() made %d RPCs, want 1", len(node.requests))
+			}
+
+			calls := node.calls("importdescriptors")
+			if len(calls) != 1 || len(calls[0].Params) != 1 {
+				t.Fatalf("ImportDescriptors() importdescriptors calls = %+v, want a single one", calls)
+			}
+
+			var requests []map[string]interface{}
+			if err := json.Unmarshal(calls[0].Params[0], &requests); err != nil {
+				t.Fatalf("invalid importdescriptors params: %v", err)
+			}
+
+			if len(requests) != len(descriptors) {
+				t.Fatalf("importdescriptors carried %d descriptors, want %d", len(requests), len(descriptors))
+			}
+
+			for i, d := range descriptors {
+				if requests[i]["desc"] != d.Value {
+					t.Errorf("importdescriptors request %d desc = %v, want %q", i, requests[i]["desc"], d.Value)
+				}
+
+				if want := tt.timestamp(d); requests[i]["timestamp"] != want {
+					t.Errorf("importdescriptors request %d timestamp = %v, want %v", i, requests[i]["timestamp"], want)
+				}
+			}
+		})
+	}
+}
+
+func TestLabelAddressesSkipsLabelled(t *testing.T) {
+	descriptors := []descriptor{
+		{Value: "wpkh([8a2b9c1d/84'/1'/0']tpubA/0/*)#aaaaaaaa", Depth: 3, Label: "savings"},
+		{Value: "wpkh([8a2b9c1d/84'/1'/0']tpubA/1/*)#bbbbbbbb", Depth: 3},
+	}
+
+	tests := []struct {
+		name     string
+		labelled string
+		rpcErr   string
+		want     []string
+	}{
+		{
+			name:     "partially labelled",
+			labelled: `{"tb1qa":{"purpose":"receive"},"tb1qb":{"purpose":"receive"}}`,
+			want:     []string{"tb1qc", "tb1qd"},
+		},
+		{
+			name:     "fully labelled",
+			labelled: `{"tb1qa":{},"tb1qb":{},"tb1qc":{},"tb1qd":{}}`,
+		},
+		{
+			name:   "unknown label",
+			rpcErr: `{"code":-11,"message":"No addresses with label savings"}`,
+			want:   []string{"tb1qa", "tb1qb", "tb1qc", "tb1qd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, client := newRecordingNode(t, map[string]string{
+				"deriveaddresses":     `["tb1qa","tb1qb","tb1qc","tb1qd"]`,
+				"getaddressesbylabel": tt.labelled,
+				"setlabel":            `null`,
+			})
+
+			if tt.rpcErr != "" {
+				node.errors["getaddressesbylabel"] = tt.rpcErr
+			}
+
+			if err := labelAddresses(client, descriptors); err != nil {
+				t.Fatalf("labelAddresses() error = %v", err)
+			}
+
+			if calls := node.calls("deriveaddresses"); len(calls) != 1 {
+				t.Errorf("labelAddresses() made %d deriveaddresses calls, want 1", len(calls))
+			}
+
+			var got []string
+			for _, call := range node.calls("setlabel") {
+				var address, label string
+				if err := json.Unmarshal(call.Params[0], &address); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := json.Unmarshal(call.Params[1], &label); err != nil {
+					t.Fatal(err)
+				}
+
+				if label != "savings" {
+					t.Errorf("setlabel(%s) label = %q, want %q", address, label, "savings")
+				}
+
+				got = append(got, address)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("labelAddresses() labelled %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWalletAsyncMissingWallet(t *testing.T) {
+	node, _ := newRecordingNode(t, map[string]string{})
+	node.errors["loadwallet"] = `{"code":-18,"message":"Wallet file verification failed."}`
+
+	b := &Bus{connCfg: node.connCfg, WalletName: "satstack"}
+
+	for i := 0; i < 2; i++ {
+		b.LoadWalletAsync()
+
+		for b.IsWalletLoading() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	if !b.IsWalletMissing() {
+		t.Fatal("IsWalletMissing() = false, want true")
+	}
+
+	if calls := node.calls("loadwallet"); len(calls) != 1 {
+		t.Errorf("LoadWalletAsync() made %d loadwallet calls, want 1", len(calls))
+	}
+}
@@ -2,6 +2,7 @@ package bus
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -11,8 +12,9 @@ import (
 	"github.com/btcsuite/btcd/rpcclient"
 
 	"github.com/btcsuite/btcd/btcjson"
-	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/ledgerhq/satstack/config"
+	"github.com/ledgerhq/satstack/types"
 	"github.com/ledgerhq/satstack/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -28,7 +30,9 @@ func waitForIBD(b *Bus) error {
 	}
 
 	for {
-		result, err := b.mainClient.RawRequest("getblockchaininfo", nil)
+		// bitcoind may still be warming up if it was restarted along with
+		// SatStack, which is not fatal.
+		result, err := rawRequestAfterWarmup(b.mainClient, "getblockchaininfo", nil)
 		if err != nil {
 			return err
 		}
@@ -68,13 +72,26 @@ func getImportProgress(b *Bus) error {
 
 	switch v := walletInfo.Scanning.Value.(type) {
 	case btcjson.ScanProgress:
+		elapsed := time.Duration(v.Duration) * time.Second
+
+		scan := NewScanProgress(v.Progress, elapsed)
+		b.importProgress.Store(scan)
+
+		eta := "unknown"
+		if scan.Remaining != nil {
+			eta = utils.HumanizeDuration(*scan.Remaining)
+		}
+
 		log.WithFields(log.Fields{
 			"prefix":   "worker",
-			"progress": fmt.Sprintf("%.2f%%", v.Progress*100),
-			"duration": utils.HumanizeDuration(
-				time.Duration(v.Duration) * time.Second),
+			"progress": fmt.Sprintf("%.2f%%", scan.Progress),
+			"duration": utils.HumanizeDuration(elapsed),
+			"eta":      eta,
 		}).Info("Importing descriptors")
 	case bool:
+		// Core briefly reports no scan in progress between the scans of
+		// consecutive descriptors. Keep the last known progress, so that it
+		// doesn't jump back to unknown.
 	default:
 		// Not scanning currently, or scan is complete.
 	}
@@ -82,15 +99,34 @@ func getImportProgress(b *Bus) error {
 	return nil
 }
 
+// ImportProgress returns the last known progress of the descriptor import
+// performed by the worker. It returns nil if no import is in progress, or if
+// its progress is not known yet.
+func (b *Bus) ImportProgress() *ScanProgress {
+	return b.importProgress.Load()
+}
+
 // ImportAccounts will import the descriptors corresponding to the accounts
 // into the Bitcoin Core wallet. This is a blocking operation.
-func (b *Bus) ImportAccounts(accounts []config.Account) error {
+//
+// Descriptors that are already watched by the wallet are skipped, so that
+// the rescan is driven only by the birthdays of the new descriptors.
+//
+// If deferredRescan is set, the descriptors are imported without triggering
+// a rescan, and a single rescan is then performed from the earliest birthday
+// among them. This avoids overlapping rescans when importing many accounts.
+func (b *Bus) ImportAccounts(accounts []config.Account, deferredRescan bool) error {
 	// Skip import of descriptors, if no account config found. SatStack
 	// will run in zero-configuration mode.
 	if accounts == nil {
 		return nil
 	}
 
+	accounts, err := b.expandAccounts(accounts)
+	if err != nil {
+		return err
+	}
+
 	client, err := b.ClientFactory()
 	if err != nil {
 		return err
@@ -98,13 +134,238 @@ func (b *Bus) ImportAccounts(accounts []config.Account) error {
 
 	defer client.Shutdown()
 
-	var allDescriptors []descriptor
+	descriptorsToImport, err := pendingDescriptors(client, accounts)
+	if err != nil {
+		return err // return bare error, since it already has a ctx
+	}
+
+	if len(descriptorsToImport) == 0 {
+		log.WithField(
+			"prefix", "worker",
+		).Info("No (new) descriptors to import")
+		return nil
+	}
+
+	if earliestAge(descriptorsToImport) == fullRescanTimestamp {
+		log.WithFields(log.Fields{
+			"prefix":      "worker",
+			"descriptors": len(descriptorsToImport),
+		}).Warn("Rescanning the ENTIRE blockchain from genesis, this can take several hours")
+	}
+
+	if !deferredRescan {
+		return ImportDescriptors(client, descriptorsToImport, true, b.WalletFormat)
+	}
+
+	if err := ImportDescriptors(client, descriptorsToImport, false, b.WalletFormat); err != nil {
+		return err
+	}
+
+	startHeight, err := heightAtTime(client, earliestAge(descriptorsToImport))
+	if err != nil {
+		return err
+	}
+
+	endHeight, err := client.GetBlockCount()
+	if err != nil {
+		return err
+	}
+
+	return b.rescanWallet(startHeight, endHeight)
+}
+
+// ImportPreview models the result of a dry-run import of accounts.
+type ImportPreview struct {
+	Descriptors []ImportPreviewDescriptor `json:"descriptors"`
+
+	// EarliestTimestamp is the UNIX timestamp from which the wallet would be
+	// rescanned, and RescanStartHeight the corresponding block height. Both
+	// are omitted if there are no descriptors to import.
+	EarliestTimestamp *uint32 `json:"earliest_timestamp,omitempty"`
+	RescanStartHeight *int64  `json:"rescan_start_height,omitempty"`
+}
+
+// ImportPreviewDescriptor models a descriptor that would be imported.
+type ImportPreviewDescriptor struct {
+	Descriptor string `json:"descriptor"`
+	Depth      int    `json:"depth"`
+	Timestamp  uint32 `json:"timestamp"`
+}
+
+// PreviewImport performs a dry-run of ImportAccounts. It canonicalizes the
+// descriptors of the accounts, and filters out those already imported, but
+// does not call importdescriptors.
+func (b *Bus) PreviewImport(accounts []config.Account) (*ImportPreview, error) {
+	accounts, err := b.expandAccounts(accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := b.ClientFactory()
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.Shutdown()
+
+	descriptorsToImport, err := pendingDescriptors(client, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &ImportPreview{
+		Descriptors: []ImportPreviewDescriptor{},
+	}
+
+	for _, descriptor := range descriptorsToImport {
+		preview.Descriptors = append(preview.Descriptors, ImportPreviewDescriptor{
+			Descriptor: descriptor.Value,
+			Depth:      descriptor.Depth,
+			Timestamp:  descriptor.Age,
+		})
+	}
+
+	if len(descriptorsToImport) == 0 {
+		return preview, nil
+	}
+
+	earliest := earliestAge(descriptorsToImport)
+
+	startHeight, err := heightAtTime(client, earliest)
+	if err != nil {
+		return nil, err
+	}
+
+	preview.EarliestTimestamp = &earliest
+	preview.RescanStartHeight = &startHeight
+
+	return preview, nil
+}
+
+// ReimportAccount imports the descriptors of a single account again in the
+// background, and rescans the wallet from the birthday of the account only.
+//
+// Unlike ImportAccounts, descriptors already watched by the wallet are not
+// skipped. Only one account can be reimported at a time, so
+// ErrReimportInProgress is returned right away if a scan or another import is
+// in progress.
+func (b *Bus) ReimportAccount(account config.Account) error {
+	if b.IsPendingScan || !b.reimporting.CompareAndSwap(false, true) {
+		return ErrReimportInProgress
+	}
+
+	go func() {
+		defer b.reimporting.Store(false)
+
+		if err := b.reimportAccount(account); err != nil {
+			log.WithFields(log.Fields{
+				"prefix": "worker",
+				"label":  account.Label,
+				"error":  err,
+			}).Error("Failed to reimport account")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"prefix": "worker",
+			"label":  account.Label,
+		}).Info("Reimported account")
+	}()
+
+	return nil
+}
+
+// reimportAccount performs the reimport of ReimportAccount. This is a
+// blocking operation.
+func (b *Bus) reimportAccount(account config.Account) error {
+	accounts, err := b.expandAccounts([]config.Account{account})
+	if err != nil {
+		return err
+	}
+
+	client, err := b.ClientFactory()
+	if err != nil {
+		return err
+	}
+
+	defer client.Shutdown()
+
+	var descriptorsToImport []descriptor
 	for _, account := range accounts {
 		accountDescriptors, err := descriptors(client, account)
 		if err != nil {
 			return err // return bare error, since it already has a ctx
 		}
 
+		descriptorsToImport = append(descriptorsToImport, accountDescriptors...)
+	}
+
+	if err := ImportDescriptors(client, descriptorsToImport, false, b.WalletFormat); err != nil {
+		return err
+	}
+
+	startHeight, err := heightAtTime(client, earliestAge(descriptorsToImport))
+	if err != nil {
+		return err
+	}
+
+	endHeight, err := client.GetBlockCount()
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"prefix":      "worker",
+		"label":       account.Label,
+		"startHeight": startHeight,
+		"endHeight":   endHeight,
+	}).Info("Reimporting account")
+
+	return b.rescanWallet(startHeight, endHeight)
+}
+
+// scanPollInterval is how often a queued import checks whether the scan in
+// progress has completed.
+const scanPollInterval = 5 * time.Second
+
+// ImportAddedAccounts imports the descriptors of accounts added to the
+// configuration while SatStack is running. This is a blocking operation.
+//
+// If a scan or another import is in progress, the import is queued until it
+// completes, so that scans never overlap.
+func (b *Bus) ImportAddedAccounts(accounts []config.Account) error {
+	queued := false
+	for b.IsPendingScan || !b.reimporting.CompareAndSwap(false, true) {
+		if !queued {
+			log.WithField(
+				"prefix", "worker",
+			).Info("Scan in progress, queuing import of added accounts")
+			queued = true
+		}
+
+		time.Sleep(scanPollInterval)
+	}
+
+	defer b.reimporting.Store(false)
+
+	b.IsPendingScan = true
+	defer func() {
+		b.IsPendingScan = false
+	}()
+
+	return b.ImportAccounts(accounts, false)
+}
+
+// pendingDescriptors returns the canonical descriptors of the accounts that
+// are not yet imported in the wallet.
+func pendingDescriptors(client *rpcclient.Client, accounts []config.Account) ([]descriptor, error) {
+	var allDescriptors []descriptor
+	for _, account := range accounts {
+		accountDescriptors, err := descriptors(client, account)
+		if err != nil {
+			return nil, err // return bare error, since it already has a ctx
+		}
+
 		allDescriptors = append(allDescriptors, accountDescriptors...)
 	}
 
@@ -112,13 +373,13 @@ func (b *Bus) ImportAccounts(accounts []config.Account) error {
 	for _, descriptor := range allDescriptors {
 		address, err := DeriveAddress(client, descriptor.Value, descriptor.Depth)
 		if err != nil {
-			return fmt.Errorf("%s (%s - #%d): %w",
+			return nil, fmt.Errorf("%s (%s - #%d): %w",
 				ErrDeriveAddress, descriptor.Value, descriptor.Depth, err)
 		}
 
 		addressInfo, err := client.GetAddressInfo(*address)
 		if err != nil {
-			return fmt.Errorf("%s (%s): %w", ErrAddressInfo, *address, err)
+			return nil, fmt.Errorf("%s (%s): %w", ErrAddressInfo, *address, err)
 		}
 
 		if !addressInfo.IsWatchOnly {
@@ -126,26 +387,53 @@ func (b *Bus) ImportAccounts(accounts []config.Account) error {
 		}
 	}
 
-	if len(descriptorsToImport) == 0 {
-		log.WithField(
-			"prefix", "worker",
-		).Info("No (new) descriptors to import")
-		return nil
+	return descriptorsToImport, nil
+}
+
+// earliestAge returns the earliest timestamp among non-empty descriptors.
+func earliestAge(descriptors []descriptor) uint32 {
+	earliest := descriptors[0].Age
+	for _, descriptor := range descriptors[1:] {
+		if descriptor.Age < earliest {
+			earliest = descriptor.Age
+		}
 	}
 
-	return ImportDescriptors(client, descriptorsToImport)
+	return earliest
 }
 
-func getPreviousRescanBlock() (int64, error) {
+// getPreviousRescanBlock returns the height up to which the wallet was
+// rescanned, according to lss_rescan.json, or -1 to trigger a full reimport.
+//
+// If the file is corrupt, or refers to a block beyond the chain tip, it is
+// backed up and ignored, instead of trusting its contents.
+func getPreviousRescanBlock(b *Bus) (int64, error) {
 
 	configRescan, err := config.LoadRescanConf()
-
-	if err != nil {
+	if errors.Is(err, config.ErrConfigFileNotFound) {
 		return -1, err
 	}
 
-	return configRescan.LastBlock, nil
+	if err == nil {
+		tip, tipErr := b.GetBlockCount()
+		if tipErr != nil || configRescan.LastBlock <= tip {
+			return configRescan.LastBlock, nil
+		}
+
+		err = fmt.Errorf("last_block %d is beyond the chain tip %d",
+			configRescan.LastBlock, tip)
+	}
+
+	backupPath, backupErr := config.BackupRescanConf()
 
+	log.WithFields(log.Fields{
+		"prefix":      "worker",
+		"error":       err,
+		"backup":      backupPath,
+		"backupError": backupErr,
+	}).Warn("Ignoring corrupt lss_rescan.json, falling back to full reimport")
+
+	return -1, err
 }
 
 // descriptors returns canonical descriptors from the account configuration.
@@ -160,12 +448,15 @@ func descriptors(client *rpcclient.Client, account config.Account) ([]descriptor
 		depth = *account.Depth
 	}
 
-	var age uint32
-	switch account.Birthday {
-	case nil:
-		age = uint32(config.BIP0039Genesis.Unix())
-	default:
-		age = uint32(account.Birthday.Unix())
+	age, err := birthdayTimestamp(client, account)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, desc := range []string{*account.External, *account.Internal} {
+		if err := VerifyDescriptorChecksum(client, desc); err != nil {
+			return nil, err
+		}
 	}
 
 	rawDescs := []string{
@@ -173,7 +464,7 @@ func descriptors(client *rpcclient.Client, account config.Account) ([]descriptor
 		strings.Split(*account.Internal, "#")[0], // strip out the checksum
 	}
 
-	for _, desc := range rawDescs {
+	for i, desc := range rawDescs {
 		canonicalDesc, err := GetCanonicalDescriptor(client, desc)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", ErrInvalidDescriptor, err)
@@ -184,55 +475,137 @@ func descriptors(client *rpcclient.Client, account config.Account) ([]descriptor
 			Depth: depth,
 			Age:   age,
 		})
+
+		// Bitcoin Core does not allow labels on change addresses.
+		if i == 0 {
+			ret[i].Label = account.Label
+		}
 	}
 
 	return ret, nil
 }
 
-// runTheNumbers performs inflation checks against the connected full node.
+// birthdayTimestamp returns the UNIX timestamp from which the descriptors of
+// an account must be scanned.
 //
-// It does NOT perform any equality comparison between expected and actual
-// supply.
-func runTheNumbers(b *Bus) error {
-	log.WithField("prefix", "worker").Info("Computing circulating supply...")
+// Birthdays specified as a block height are converted to the time of the
+// corresponding block, and "now" to the time of the current chain tip, which
+// skips scanning historical blocks altogether. A "genesis" birthday maps to
+// the timestamp 0, which scans the entire blockchain.
+func birthdayTimestamp(client *rpcclient.Client, account config.Account) (uint32, error) {
+	birthday := account.Birthday
+
+	switch {
+	case birthday == nil:
+		return uint32(config.BIP0039Genesis.Unix()), nil
+	case birthday.Genesis:
+		return fullRescanTimestamp, nil
+	case birthday.IsTime():
+		return uint32(birthday.Unix()), nil
+	}
+
+	var (
+		hash *chainhash.Hash
+		err  error
+	)
+
+	if birthday.Now {
+		hash, err = client.GetBestBlockHash()
+	} else {
+		hash, err = client.GetBlockHash(*birthday.Height)
+	}
 
-	info, err := b.mainClient.GetTxOutSetInfo()
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
 	}
 
-	const halvingBlocks = 210000
+	header, err := client.GetBlockHeaderVerbose(hash)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
+	}
+
+	return uint32(header.Time), nil
+}
+
+// heightAtTime returns the height of the first block whose timestamp is not
+// older than the given UNIX timestamp, minus the rescan window.
+//
+// Block timestamps are not strictly monotonic, so the result is the same
+// approximation Bitcoin Core makes when importing descriptors with a
+// timestamp.
+func heightAtTime(client *rpcclient.Client, timestamp uint32) (int64, error) {
+	target := int64(timestamp) - rescanWindow
+
+	count, err := client.GetBlockCount()
+	if err != nil {
+		return 0, err
+	}
+
+	return searchHeight(count, func(height int64) (bool, error) {
+		hash, err := client.GetBlockHash(height)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
+		}
+
+		header, err := client.GetBlockHeaderVerbose(hash)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", ErrFailedToGetBlock, err)
+		}
+
+		return header.Time < target, nil
+	})
+}
+
+// runTheNumbers performs inflation checks against the connected full node.
+//
+// If height is negative, the check is performed against the UTXO set at the
+// chain tip. Otherwise, it is performed at the given historical height. The
+// result is reported by Bus.Supply.
+func runTheNumbers(b *Bus, height int64) error {
+	log.WithField("prefix", "worker").Info("Computing circulating supply...")
 
 	var (
-		subsidy float64 = 50
-		supply  float64 = 0
+		report *types.SupplyReport
+		err    error
 	)
 
-	i := int64(0)
-	for ; i < info.Height/halvingBlocks; i++ {
-		supply += halvingBlocks * subsidy
-		subsidy /= 2
+	switch {
+	case height < 0:
+		info, err := b.mainClient.GetTxOutSetInfo()
+		if err != nil {
+			return err
+		}
+
+		report = b.newSupplyReport(info.Height, info.TotalAmount)
+	default:
+		report, err = b.VerifySupplyAtHeight(height)
+		if err != nil {
+			return err
+		}
 	}
 
-	supply += subsidy * float64(info.Height-(halvingBlocks*i))
+	b.supply.Store(report)
 
-	supplyBTC, err := btcutil.NewAmount(supply)
-	if err != nil {
-		return err
+	fields := log.WithFields(log.Fields{
+		"prefix":         "worker",
+		"height":         report.Height,
+		"expectedSupply": report.ExpectedSupply,
+		"actualSupply":   report.ActualSupply,
+		"difference":     report.Difference,
+	})
+
+	if report.Mismatch {
+		fields.Warn("#RunTheNumbers detected a supply mismatch")
+		return nil
 	}
 
-	log.WithFields(log.Fields{
-		"prefix":         "worker",
-		"height":         info.Height,
-		"expectedSupply": supplyBTC,
-		"actualSupply":   info.TotalAmount,
-	}).Info("#RunTheNumbers successful")
+	fields.Info("#RunTheNumbers successful")
 
 	return nil
 }
 
 func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
-	forceImportDesc bool) {
+	circulationCheckHeight int64, forceImportDesc bool, deferredRescan bool) {
 	importDone := make(chan bool)
 
 	sendInterruptSignal := func() {
@@ -270,7 +643,7 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 		if circulationCheck {
 			b.IsPendingScan = true
 
-			if err := runTheNumbers(b); err != nil {
+			if err := runTheNumbers(b, circulationCheckHeight); err != nil {
 				log.WithFields(log.Fields{
 					"prefix": "worker",
 					"error":  err,
@@ -285,7 +658,7 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 		}
 
 		// We check whether the lss_rescan.json exists
-		startHeight, err := getPreviousRescanBlock()
+		startHeight, err := getPreviousRescanBlock(b)
 		if err != nil {
 			log.Debugf("No lss_rescan.json was found: %s", err)
 		}
@@ -314,7 +687,7 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 
 				if b.IsPendingScan {
 					// Interrupt Scan
-					err = b.AbortRescan()
+					_, err = b.AbortRescan()
 					if err != nil {
 						sendInterruptSignal()
 						return
@@ -326,7 +699,13 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 			// and will automatically trigger a wallet scan
 			b.IsPendingScan = true
 
-			if err := b.ImportAccounts(config.Accounts); err != nil {
+			err := b.ImportAccounts(config.Accounts, deferredRescan)
+			if errors.Is(err, ErrRescanAborted) {
+				importDone <- true
+				return
+			}
+
+			if err != nil {
 				log.WithFields(log.Fields{
 					"prefix": "worker",
 					"error":  err,
@@ -352,7 +731,7 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 			}
 
 			if b.IsPendingScan {
-				err := b.AbortRescan()
+				_, err := b.AbortRescan()
 				if err != nil {
 					log.WithFields(log.Fields{
 						"error": err,
@@ -360,10 +739,36 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 				}
 			}
 
+			// Import the descriptors of accounts added to the config since
+			// the last run, if any. The rescan this triggers only starts from
+			// the birthday of these new descriptors.
+			err = b.ImportAccounts(config.Accounts, deferredRescan)
+			if errors.Is(err, ErrRescanAborted) {
+				importDone <- true
+				return
+			}
+
+			if err != nil {
+				log.WithFields(log.Fields{
+					"prefix": "worker",
+					"error":  err,
+				}).Error("Failed while importing new descriptors")
+
+				sendInterruptSignal()
+				return
+			}
+
 			endHeight, _ := b.GetBlockCount()
 
 			// Begin Starting rescan, this is a blocking call
 			err = b.rescanWallet(startHeight, endHeight)
+			if errors.Is(err, ErrRescanAborted) {
+				// Progress was checkpointed after the last rescanned window,
+				// so the rescan resumes from there on the next startup.
+				importDone <- true
+				return
+			}
+
 			if err != nil {
 				log.WithFields(log.Fields{
 					"prefix": "worker",
@@ -374,6 +779,15 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 			}
 		}
 
+		if config.Accounts != nil {
+			if _, err := b.CheckDescriptorDrift(config.Accounts); err != nil {
+				log.WithFields(log.Fields{
+					"prefix": "worker",
+					"error":  err,
+				}).Error("Failed to check wallet descriptors")
+			}
+		}
+
 		err = b.DumpLatestRescanTime()
 		if err != nil {
 			log.WithFields(log.Fields{
@@ -388,6 +802,7 @@ func (b *Bus) Worker(config *config.Configuration, circulationCheck bool,
 	go func() {
 		defer func() {
 			close(importDone)
+			b.importProgress.Store(nil)
 
 			log.WithFields(log.Fields{
 				"prefix": "worker",
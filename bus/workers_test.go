@@ -0,0 +1,44 @@
+package bus
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ledgerhq/satstack/config"
+)
+
+func TestReimportAccountInProgress(t *testing.T) {
+	// The node does not answer until released, which keeps the first
+	// reimport in progress.
+	release := make(chan struct{})
+	connCfg := serveStubNode(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}), false)
+
+	b := &Bus{connCfg: connCfg}
+
+	external, internal := "wpkh(tpubA/0/*)", "wpkh(tpubA/1/*)"
+	account := config.Account{Label: "savings", External: &external, Internal: &internal}
+
+	if err := b.ReimportAccount(account); err != nil {
+		t.Fatalf("ReimportAccount() error = %v", err)
+	}
+
+	if err := b.ReimportAccount(account); !errors.Is(err, ErrReimportInProgress) {
+		t.Errorf("ReimportAccount() while reimporting error = %v, want %v", err, ErrReimportInProgress)
+	}
+
+	close(release)
+
+	for b.reimporting.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	b.IsPendingScan = true
+	if err := b.ReimportAccount(account); !errors.Is(err, ErrReimportInProgress) {
+		t.Errorf("ReimportAccount() while scanning error = %v, want %v", err, ErrReimportInProgress)
+	}
+}
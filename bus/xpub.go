@@ -0,0 +1,319 @@
+package bus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/ledgerhq/satstack/config"
+	"github.com/ledgerhq/satstack/types"
+)
+
+// serializedKeyLen is the length of a serialized extended key, excluding the
+// base58 checksum.
+const serializedKeyLen = 78
+
+// accountKeyDepth is the depth of account-level extended keys, as defined in
+// BIP44 (m / purpose' / coin_type' / account').
+const accountKeyDepth = 3
+
+// schemePurposes maps the script schemes supported in the xpub account
+// config to their BIP43 purpose. The scheme names are the same as the ones
+// of the --scheme option of scripts/getdescriptor.
+var schemePurposes = map[string]uint32{
+	"legacy":        44,
+	"segwit":        49,
+	"native_segwit": 84,
+	"taproot":       86,
+}
+
+// slip132Versions maps the version bytes of extended public keys, including
+// the SLIP-132 variants (ypub, zpub, ...), to the only scheme they may be
+// used with, by network. Plain xpub and tpub keys are used with any scheme.
+var slip132Versions = map[bool]map[[4]byte]string{
+	// Mainnet: xpub, ypub, zpub
+	true: {
+		{0x04, 0x88, 0xb2, 0x1e}: "",
+		{0x04, 0x9d, 0x7c, 0xb2}: "segwit",
+		{0x04, 0xb2, 0x47, 0x46}: "native_segwit",
+	},
+	// Testnet, regtest and signet: tpub, upub, vpub
+	false: {
+		{0x04, 0x35, 0x87, 0xcf}: "",
+		{0x04, 0x4a, 0x52, 0x62}: "segwit",
+		{0x04, 0x5f, 0x1c, 0xf6}: "native_segwit",
+	},
+}
+
+// accountDescriptors returns the external and internal descriptors of an
+// account specified by its extended public key and script scheme.
+//
+// SLIP-132 keys are converted to the xpub (or tpub) format understood by
+// Bitcoin Core. The key origin is included if the master fingerprint is
+// configured, and the key is at the account level.
+func accountDescriptors(account config.Account, params *chaincfg.Params) (string, string, error) {
+	purpose, ok := schemePurposes[account.Scheme]
+	if !ok {
+		return "", "", fmt.Errorf("%w: unknown scheme '%s'", ErrInvalidXPub, account.Scheme)
+	}
+
+	payload, err := decodeExtendedKey(*account.XPub, account.Scheme, params)
+	if err != nil {
+		return "", "", err
+	}
+
+	key := base58.Encode(append(payload, checksum(payload)...))
+
+	var origin string
+	if account.Fingerprint != nil {
+		depth := payload[4]
+		index := binary.BigEndian.Uint32(payload[9:13])
+
+		if depth != accountKeyDepth || index < hdkeychain.HardenedKeyStart {
+			return "", "", fmt.Errorf(
+				"%w: fingerprint requires an account-level key, got depth %d",
+				ErrInvalidXPub, depth)
+		}
+
+		origin = fmt.Sprintf("[%s/%d'/%d'/%d']", *account.Fingerprint,
+			purpose, params.HDCoinType, index-hdkeychain.HardenedKeyStart)
+	}
+
+	descriptor := func(change int) string {
+		fragment := fmt.Sprintf("%s%s/%d/*", origin, key, change)
+
+		switch account.Scheme {
+		case "legacy":
+			return fmt.Sprintf("pkh(%s)", fragment)
+		case "segwit":
+			return fmt.Sprintf("sh(wpkh(%s))", fragment)
+		case "taproot":
+			return fmt.Sprintf("tr(%s)", fragment)
+		default:
+			return fmt.Sprintf("wpkh(%s)", fragment)
+		}
+	}
+
+	return descriptor(0), descriptor(1), nil
+}
+
+// decodeExtendedKey decodes a base58 extended public key, and returns its
+// serialization with the version bytes of the given network. SLIP-132 keys
+// must match the given scheme.
+func decodeExtendedKey(xpub string, scheme string, params *chaincfg.Params) ([]byte, error) {
+	decoded := base58.Decode(xpub)
+	if len(decoded) != serializedKeyLen+4 {
+		return nil, fmt.Errorf("%w: bad length", ErrInvalidXPub)
+	}
+
+	payload := decoded[:serializedKeyLen]
+	if !bytes.Equal(checksum(payload), decoded[serializedKeyLen:]) {
+		return nil, fmt.Errorf("%w: bad checksum", ErrInvalidXPub)
+	}
+
+	isMainnet := params.Net == chaincfg.MainNetParams.Net
+
+	var version [4]byte
+	copy(version[:], payload[:4])
+
+	versionScheme, ok := slip132Versions[isMainnet][version]
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected version %s for network %s",
+			ErrInvalidXPub, hex.EncodeToString(version[:]), params.Name)
+	}
+
+	if versionScheme != "" && versionScheme != scheme {
+		return nil, fmt.Errorf("%w: version %s is for scheme '%s', not '%s'",
+			ErrInvalidXPub, hex.EncodeToString(version[:]), versionScheme, scheme)
+	}
+
+	copy(payload[:4], params.HDPublicKeyID[:])
+	return payload, nil
+}
+
+// checksum returns the base58check checksum of the given payload.
+func checksum(payload []byte) []byte {
+	return chainhash.DoubleHashB(payload)[:4]
+}
+
+// expandAccounts returns the accounts, with the descriptors of accounts
+// specified by an extended public key filled in.
+func (b *Bus) expandAccounts(accounts []config.Account) ([]config.Account, error) {
+	expanded := make([]config.Account, 0, len(accounts))
+
+	for _, account := range accounts {
+		if account.XPub != nil {
+			external, internal, err := accountDescriptors(account, b.Params)
+			if err != nil {
+				return nil, err
+			}
+
+			account.External = &external
+			account.Internal = &internal
+		}
+
+		expanded = append(expanded, account)
+	}
+
+	return expanded, nil
+}
+
+// DefaultGapLimit is the number of consecutive unused addresses after which
+// the addresses of an account are no longer scanned, as defined by BIP44.
+const DefaultGapLimit = 20
+
+// MaxGapLimit is the largest gap limit accepted by GetXPubBalance.
+const MaxGapLimit = 1000
+
+// keyOriginRegexp matches the key origins of a descriptor, in the form
+// [fingerprint/path].
+var keyOriginRegexp = regexp.MustCompile(`\[[^\]]*\]`)
+
+// GetXPubBalance returns the aggregate balance of the account of the given
+// extended public key and script scheme, across its receive and change
+// addresses, with at least minConf confirmations.
+//
+// The descriptors of the account must be imported in the wallet, regardless
+// of their key origin. Addresses are scanned in order until gapLimit
+// consecutive ones have never received any funds.
+func (b *Bus) GetXPubBalance(xpub string, scheme string, gapLimit int, minConf int) (*types.XPubBalance, error) {
+	if gapLimit <= 0 || gapLimit > MaxGapLimit {
+		return nil, fmt.Errorf("%w: gap limit %d, expected 1 to %d",
+			ErrInvalidGapLimit, gapLimit, MaxGapLimit)
+	}
+
+	external, internal, err := accountDescriptors(config.Account{
+		XPub:   &xpub,
+		Scheme: scheme,
+	}, b.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := b.listDescriptors()
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := b.usedAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.XPubBalance{}
+
+	var addresses []string
+	for _, desc := range []string{external, internal} {
+		canonicalDesc, err := GetCanonicalDescriptor(b.mainClient, desc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+
+		if !isImported(imported, *canonicalDesc) {
+			return nil, fmt.Errorf("%w: %s", ErrDescriptorNotImported, *canonicalDesc)
+		}
+
+		usedAddresses, err := b.scanUsedAddresses(*canonicalDesc, used, gapLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Descriptors = append(result.Descriptors, *canonicalDesc)
+		addresses = append(addresses, usedAddresses...)
+	}
+
+	result.UsedAddresses = len(addresses)
+
+	// Only used addresses can hold UTXOs. Besides, listunspent would return
+	// the UTXOs of the whole wallet if no address is given.
+	if len(addresses) == 0 {
+		return result, nil
+	}
+
+	balance, err := b.GetBalance(addresses, minConf)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Balance = *balance
+
+	return result, nil
+}
+
+// scanUsedAddresses derives the addresses of a ranged descriptor, in windows
+// of gapLimit addresses, and returns those that are used, until gapLimit
+// consecutive addresses are unused.
+func (b *Bus) scanUsedAddresses(descriptor string, used map[string]bool, gapLimit int) ([]string, error) {
+	var addresses []string
+
+	lastUsed := -1
+	for start := 0; start-lastUsed <= gapLimit; start += gapLimit {
+		derived, err := b.mainClient.DeriveAddresses(descriptor,
+			&btcjson.DescriptorRange{Value: []int{start, start + gapLimit - 1}})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDescriptor, err)
+		}
+
+		for i, address := range *derived {
+			if used[address] {
+				addresses = append(addresses, address)
+				lastUsed = start + i
+			}
+		}
+	}
+
+	return addresses, nil
+}
+
+// usedAddresses returns the wallet addresses that have received funds, in
+// confirmed or unconfirmed transactions.
+func (b *Bus) usedAddresses() (map[string]bool, error) {
+	params := make([]json.RawMessage, 0, 3)
+	for _, param := range []interface{}{0, false, true} { // minconf, include_empty, include_watchonly
+		raw, err := json.Marshal(param)
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, raw)
+	}
+
+	raw, err := b.mainClient.RawRequest("listreceivedbyaddress", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []btcjson.ListReceivedByAddressResult
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool, len(results))
+	for _, result := range results {
+		used[result.Address] = true
+	}
+
+	return used, nil
+}
+
+// isImported reports whether a descriptor is imported in the wallet,
+// regardless of its checksum and key origins.
+func isImported(imported *listDescriptorsResult, descriptor string) bool {
+	stripped := keyOriginRegexp.ReplaceAllString(normalizeDescriptor(descriptor), "")
+
+	for _, desc := range imported.Descriptors {
+		if keyOriginRegexp.ReplaceAllString(normalizeDescriptor(desc.Descriptor), "") == stripped {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package bus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestDecodeExtendedKeySchemes(t *testing.T) {
+	params := &chaincfg.TestNet3Params
+
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{0x42}, hdkeychain.RecommendedSeedLen), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accountKey, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// withVersion returns the account key serialized with the given version
+	// bytes.
+	withVersion := func(version [4]byte) string {
+		payload := base58.Decode(accountKey.String())[:serializedKeyLen]
+		copy(payload[:4], version[:])
+		return base58.Encode(append(payload, checksum(payload)...))
+	}
+
+	tpub := withVersion([4]byte{0x04, 0x35, 0x87, 0xcf})
+	upub := withVersion([4]byte{0x04, 0x4a, 0x52, 0x62})
+	vpub := withVersion([4]byte{0x04, 0x5f, 0x1c, 0xf6})
+	zpub := withVersion([4]byte{0x04, 0xb2, 0x47, 0x46})
+
+	tests := []struct {
+		name   string
+		key    string
+		scheme string
+		valid  bool
+	}{
+		{name: "tpub legacy", key: tpub, scheme: "legacy", valid: true},
+		{name: "tpub taproot", key: tpub, scheme: "taproot", valid: true},
+		{name: "upub segwit", key: upub, scheme: "segwit", valid: true},
+		{name: "vpub native_segwit", key: vpub, scheme: "native_segwit", valid: true},
+		{name: "upub native_segwit", key: upub, scheme: "native_segwit"},
+		{name: "vpub segwit", key: vpub, scheme: "segwit"},
+		{name: "vpub legacy", key: vpub, scheme: "legacy"},
+		{name: "vpub taproot", key: vpub, scheme: "taproot"},
+		{name: "mainnet zpub", key: zpub, scheme: "native_segwit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := decodeExtendedKey(tt.key, tt.scheme, params)
+
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidXPub) {
+					t.Fatalf("decodeExtendedKey() error = %v, want %v", err, ErrInvalidXPub)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("decodeExtendedKey() error = %v", err)
+			}
+
+			if !bytes.Equal(payload[:4], params.HDPublicKeyID[:]) {
+				t.Errorf("decodeExtendedKey() version = %x, want %x", payload[:4], params.HDPublicKeyID)
+			}
+		})
+	}
+}
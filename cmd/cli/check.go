@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ledgerhq/satstack/bus"
+	"github.com/ledgerhq/satstack/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
+
+// checkCmd validates the config against the connected node, without starting
+// the explorer API. It is meant for CI and deployment pipelines.
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the config against the connected Bitcoin node, then exit.",
+	Long: `Check loads and validates the config, then verifies that bitcoind is reachable, ` +
+		`accepts the RPC credentials, runs on the expected chain, and accepts the account ` +
+		`descriptors. It also reports txindex, and whether the wallet exists. The command ` +
+		`exits with a non-zero status if any check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Keep the report readable, by only logging failures.
+		log.SetLevel(log.WarnLevel)
+
+		failed := false
+
+		report := func(result bus.CheckResult) {
+			switch {
+			case result.Err != nil:
+				failed = true
+				fmt.Printf("[FAIL] %s: %s\n", result.Name, result.Err)
+			case result.Detail != "":
+				fmt.Printf("[PASS] %s: %s\n", result.Name, result.Detail)
+			default:
+				fmt.Printf("[PASS] %s\n", result.Name)
+			}
+		}
+
+		configuration, err := config.Load()
+		if err != nil {
+			report(bus.CheckResult{Name: "config", Err: err})
+			os.Exit(1)
+		}
+
+		report(bus.CheckResult{Name: "config"})
+
+		for _, result := range bus.Check(configuration) {
+			report(result)
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/ledgerhq/satstack/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const modulePath = "github.com/ledgerhq/satstack/"
+
+// setJSONLogging switches the standard logger to the JSON formatter, suitable
+// for ingestion into log pipelines.
+//
+// Each log entry is tagged with a "component" field, to allow filtering logs
+// emitted by the bus, worker and httpd packages.
+func setJSONLogging() {
+	log.SetReportCaller(true)
+	log.SetFormatter(&log.JSONFormatter{
+		TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+
+		// The caller is only used to compute the component field, and is
+		// omitted from the output.
+		CallerPrettyfier: func(*runtime.Frame) (string, string) {
+			return "", ""
+		},
+	})
+	log.AddHook(componentHook{})
+}
+
+// componentHook is a logrus hook that populates the "component" field of log
+// entries, unless already set.
+//
+// Logs with the "worker" prefix are attributed to the worker. Otherwise, the
+// component is derived from the package of the caller, ex: bus, httpd, config.
+type componentHook struct{}
+
+func (componentHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (componentHook) Fire(entry *log.Entry) error {
+	if _, ok := entry.Data["component"]; ok {
+		return nil
+	}
+
+	if prefix, ok := entry.Data["prefix"]; ok && prefix == "worker" {
+		entry.Data["component"] = "worker"
+		return nil
+	}
+
+	if entry.Caller != nil {
+		entry.Data["component"] = componentFromFunction(entry.Caller.Function)
+	}
+
+	return nil
+}
+
+// componentFromFunction returns the top-level package of SatStack that a
+// fully qualified function name belongs to.
+//
+// Example:
+//
+//	github.com/ledgerhq/satstack/httpd/svc.(*Service).GetStatus -> httpd
+func componentFromFunction(function string) string {
+	if !strings.HasPrefix(function, modulePath) {
+		return "lss"
+	}
+
+	name := strings.TrimPrefix(function, modulePath)
+	if idx := strings.IndexAny(name, "/."); idx >= 0 {
+		name = name[:idx]
+	}
+
+	return name
+}
+
+// requestIDHook is a logrus hook that populates the "requestID" field of log
+// entries created with log.WithContext, if the context carries a request ID.
+type requestIDHook struct{}
+
+func (requestIDHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (requestIDHook) Fire(entry *log.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	if id := utils.RequestID(entry.Context); id != "" {
+		entry.Data["requestID"] = id
+	}
+
+	return nil
+}
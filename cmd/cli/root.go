@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,10 +23,46 @@ import (
 
 func init() {
 	rootCmd.PersistentFlags().String("port", "20000", "Port")
+	rootCmd.PersistentFlags().String("listen", "", "address to listen on, ex: 127.0.0.1:20000 (overrides --port)")
+	rootCmd.PersistentFlags().StringSlice("listeners", nil, "comma-separated listeners in the "+
+		"ADDRESS[=ROUTES[+auth]] format, where ROUTES is all, explorer or control, and +auth requires the API "+
+		"token for explorer endpoints, ex: 127.0.0.1:20000=control,192.168.1.10:20001=explorer "+
+		"(overrides --listen and --port)")
+	rootCmd.PersistentFlags().Duration("shutdown-timeout", 10*time.Second, "time to wait for in-flight requests "+
+		"to complete on shutdown")
+	rootCmd.PersistentFlags().Int("max-concurrent-requests", 0, "maximum number of explorer requests processed "+
+		"concurrently, 0 for no limit")
+	rootCmd.PersistentFlags().Duration("queue-timeout", 0, "time that explorer requests over the concurrency limit "+
+		"wait for a slot, before being rejected with 429")
+	rootCmd.PersistentFlags().Bool("gzip", true, "compress large responses for clients sending Accept-Encoding: gzip")
+	rootCmd.PersistentFlags().Duration("status-cache-ttl", 2*time.Second, "time during which the explorer status "+
+		"is served from memory, 0 to disable")
+	rootCmd.PersistentFlags().Duration("status-grace-period", 10*time.Second, "time during which the last known "+
+		"good explorer status is returned while the node is unreachable, 0 to disable")
+	rootCmd.PersistentFlags().Duration("fees-cache-ttl", 30*time.Second, "time during which fee estimates "+
+		"are served from memory, 0 to disable")
+	rootCmd.PersistentFlags().Duration("health-max-tip-age", bus.DefaultHealthThresholds.MaxTipAge, "age of the "+
+		"chain tip beyond which the health check fails, 0 to disable")
+	rootCmd.PersistentFlags().Float64("health-min-sync-progress", bus.DefaultHealthThresholds.MinSyncProgress,
+		"verification progress (in percent) below which the health check fails, 0 to disable")
+	rootCmd.PersistentFlags().Int64("health-min-peers", bus.DefaultHealthThresholds.MinPeers, "number of "+
+		"connected peers below which the health check fails, 0 to disable")
+	rootCmd.PersistentFlags().StringSlice("rpc-allowlist", bus.DefaultRPCAllowlist, "comma-separated RPC methods "+
+		"that can be called through /control/rpc; dangerous methods are always denied")
+	rootCmd.PersistentFlags().StringSlice("cors-allowed-origins", nil, "comma-separated origins of browser-based "+
+		"clients allowed to call SatStack, or * for any (default: same-origin only)")
+	rootCmd.PersistentFlags().StringSlice("cors-allowed-methods", []string{"GET", "POST"}, "comma-separated methods "+
+		"allowed in cross-origin requests")
+	rootCmd.PersistentFlags().StringSlice("cors-allowed-headers", []string{"Authorization", "Content-Type", "X-API-Key",
+		"X-Request-ID"}, "comma-separated headers allowed in cross-origin requests")
 	rootCmd.PersistentFlags().Bool("unload-wallet", false, "whether SatStack should unload wallet")
 	rootCmd.PersistentFlags().Bool("circulation-check", false, "performs inflation checks against the connected full node")
+	rootCmd.PersistentFlags().Int64("circulation-check-height", -1, "block height at which to perform the inflation checks "+
+		"(requires coinstatsindex=1); defaults to the chain tip")
 	rootCmd.PersistentFlags().Bool("force-importdescriptors", false, "this will force importing descriptors although the wallet does already exist "+
 		"which will force the wallet to rescan from the brithday date")
+	rootCmd.PersistentFlags().Bool("deferred-rescan", false, "import all descriptors without rescanning, then perform a single "+
+		"rescan from the earliest account birthday")
 
 }
 
@@ -34,40 +72,174 @@ var rootCmd = &cobra.Command{
 	Long:  `Ledger SatStack is a lightweight bridge to connect Ledger Live with your personal Bitcoin full node. It's designed to allow Ledger Live users use Bitcoin without compromising on privacy, or relying on Ledger's infrastructure.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		port, _ := cmd.Flags().GetString("port")
+		listen, _ := cmd.Flags().GetString("listen")
+		listenerSpecs, _ := cmd.Flags().GetStringSlice("listeners")
+		shutdownTimeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+		maxConcurrentRequests, _ := cmd.Flags().GetInt("max-concurrent-requests")
+		queueTimeout, _ := cmd.Flags().GetDuration("queue-timeout")
+		gzip, _ := cmd.Flags().GetBool("gzip")
+		statusCacheTTL, _ := cmd.Flags().GetDuration("status-cache-ttl")
+		feesCacheTTL, _ := cmd.Flags().GetDuration("fees-cache-ttl")
+		statusGracePeriod, _ := cmd.Flags().GetDuration("status-grace-period")
+		healthMaxTipAge, _ := cmd.Flags().GetDuration("health-max-tip-age")
+		healthMinSyncProgress, _ := cmd.Flags().GetFloat64("health-min-sync-progress")
+		healthMinPeers, _ := cmd.Flags().GetInt64("health-min-peers")
+		rpcAllowlist, _ := cmd.Flags().GetStringSlice("rpc-allowlist")
+		corsAllowedOrigins, _ := cmd.Flags().GetStringSlice("cors-allowed-origins")
+		corsAllowedMethods, _ := cmd.Flags().GetStringSlice("cors-allowed-methods")
+		corsAllowedHeaders, _ := cmd.Flags().GetStringSlice("cors-allowed-headers")
 		unloadWallet, _ := cmd.Flags().GetBool("unload-wallet")
 		circulationCheck, _ := cmd.Flags().GetBool("circulation-check")
+		circulationCheckHeight, _ := cmd.Flags().GetInt64("circulation-check-height")
 		forceImportDesc, _ := cmd.Flags().GetBool("force-importdescriptors")
+		deferredRescan, _ := cmd.Flags().GetBool("deferred-rescan")
 
-		s := startup(unloadWallet, circulationCheck, forceImportDesc)
+		if listen == "" {
+			listen = ":" + port
+		}
+
+		listeners := []httpd.Listener{{Address: listen, Routes: httpd.AllRoutes}}
+		if len(listenerSpecs) > 0 {
+			listeners = listeners[:0]
+			for _, spec := range listenerSpecs {
+				listener, err := httpd.ParseListener(spec)
+				if err != nil {
+					log.WithField("error", err).Fatal("Invalid --listeners flag")
+					return
+				}
+
+				listeners = append(listeners, listener)
+			}
+		}
+
+		s, configuration := startup(unloadWallet, circulationCheck, circulationCheckHeight, forceImportDesc, deferredRescan)
 		if s == nil {
 			return
 		}
 
-		engine := httpd.GetRouter(s)
+		s.EnableResponseCache(statusCacheTTL, feesCacheTTL)
+		s.SetStatusGracePeriod(statusGracePeriod)
+		s.SetHealthThresholds(bus.HealthThresholds{
+			MaxTipAge:       healthMaxTipAge,
+			MinSyncProgress: healthMinSyncProgress,
+			MinPeers:        healthMinPeers,
+		})
+		s.SetRPCAllowlist(rpcAllowlist)
+
+		// The concurrency limit protects the node, so it is shared by all the
+		// listeners.
+		limiter := httpd.NewConcurrencyLimiter(maxConcurrentRequests, queueTimeout)
+
+		servers := make([]*http.Server, 0, len(listeners))
+		for _, listener := range listeners {
+			if listener.RequireToken && configuration.APIToken == "" {
+				log.WithField("address", listener.Address).Fatal(
+					"Listener requires the API token, but no api_token is configured")
+				return
+			}
+
+			// Listeners set with --listeners separate the control endpoints,
+			// which must then be protected by the API token.
+			if len(listenerSpecs) > 0 && listener.Routes.Serves(httpd.ControlRoutes) && configuration.APIToken == "" {
+				log.WithField("address", listener.Address).Fatal(
+					"Listener serves the control endpoints, but no api_token is configured")
+				return
+			}
 
-		srv := &http.Server{
-			Addr:    ":" + port,
-			Handler: engine,
+			engine := httpd.GetRouter(s, httpd.Options{
+				Limiter:            limiter,
+				Gzip:               gzip,
+				APIToken:           configuration.APIToken,
+				ProtectExplorer:    configuration.APITokenExplorer || listener.RequireToken,
+				CORSAllowedOrigins: corsAllowedOrigins,
+				CORSAllowedMethods: corsAllowedMethods,
+				CORSAllowedHeaders: corsAllowedHeaders,
+				Routes:             listener.Routes,
+			})
+
+			srv := &http.Server{
+				Addr:    listener.Address,
+				Handler: engine,
+			}
+			servers = append(servers, srv)
+
+			log.WithFields(log.Fields{
+				"address": listener.Address,
+				"routes":  listener.Routes,
+			}).Info("Listening for requests")
+
+			go func() {
+				// service connections
+				if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.WithFields(log.Fields{
+						"address": srv.Addr,
+						"error":   err,
+					}).Fatal("Failed to listen and serve")
+				}
+			}()
 		}
 
+		// Reload the accounts of the config on SIGHUP. Reloads are handled
+		// one at a time, and signals received meanwhile are coalesced into a
+		// single queued reload.
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+
 		go func() {
-			// service connections
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.WithFields(log.Fields{
-					"error": err,
-				}).Fatal("Failed to listen and serve")
+			for range hup {
+				log.Info("Reloading config")
+
+				reloaded, err := config.Load()
+				if err != nil {
+					log.WithField("error", err).Error("Failed to reload config, keeping the current one")
+					continue
+				}
+
+				if err := s.ReloadAccounts(reloaded.Accounts); err != nil {
+					log.WithField("error", err).Error("Failed to import accounts added to config")
+					continue
+				}
+
+				log.Info("Reloaded config")
 			}
 		}()
 
-		// Wait for interrupt signal to gracefully shutdown the server with
-		// a timeout of 5 seconds.
-		quit := make(chan os.Signal)
-		signal.Notify(quit, os.Interrupt)
+		// Wait for an interrupt or termination signal to gracefully shutdown
+		// the server. The worker also interrupts the process on fatal errors.
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 		<-quit
 
 		log.Info("Shutdown server: in progress")
 
+		{
+			// Scoped block to stop accepting new connections, and drain the
+			// in-flight requests before the RPC clients they rely on are
+			// closed.
+
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			var wg sync.WaitGroup
+			for _, srv := range servers {
+				wg.Add(1)
+				go func(srv *http.Server) {
+					defer wg.Done()
+
+					if err := srv.Shutdown(ctx); err != nil {
+						log.WithFields(log.Fields{
+							"address": srv.Addr,
+							"error":   err,
+						}).Error("Failed to drain in-flight requests")
+					}
+				}(srv)
+			}
+			wg.Wait()
+
+			log.Info("Shutdown server: done")
+		}
+
 		{
 
 			// In case we are scanning the wallet, we have to abort the wallet
@@ -77,7 +249,7 @@ var rootCmd = &cobra.Command{
 
 			if s.Bus.IsPendingScan {
 
-				err := s.Bus.AbortRescan()
+				_, err := s.Bus.AbortRescan()
 				if err != nil {
 					log.WithFields(log.Fields{
 						"error": err,
@@ -101,19 +273,8 @@ var rootCmd = &cobra.Command{
 			defer cancel()
 
 			s.Bus.Close(ctx)
-		}
-
-		{
-			// Scoped block to gracefully shutdown Gin-Gonic server within 10s.
-
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel()
 
-			if err := srv.Shutdown(ctx); err != nil {
-				log.WithField("error", err).Fatal("Failed to shutdown server")
-			}
-
-			log.Info("Shutdown server: done")
+			log.Info("Shutdown bus: done")
 		}
 	},
 }
@@ -124,7 +285,8 @@ func Execute() {
 	}
 }
 
-func startup(unloadWallet bool, circulationCheck bool, forceImportDesc bool) *svc.Service {
+func startup(unloadWallet bool, circulationCheck bool, circulationCheckHeight int64,
+	forceImportDesc bool, deferredRescan bool) (*svc.Service, *config.Configuration) {
 	gin.SetMode(gin.ReleaseMode)
 
 	if version.Build == "development" {
@@ -138,6 +300,8 @@ func startup(unloadWallet bool, circulationCheck bool, forceImportDesc bool) *sv
 		SpacePadding:     45,
 	})
 
+	log.AddHook(requestIDHook{})
+
 	log.WithFields(log.Fields{
 		"build":   version.Build,
 		"commit":  version.GitCommit,
@@ -150,26 +314,24 @@ func startup(unloadWallet bool, circulationCheck bool, forceImportDesc bool) *sv
 		log.WithFields(log.Fields{
 			"error": err.Error(),
 		}).Fatal("Failed to load config")
-		return nil
+		return nil, nil
+	}
+
+	if configuration.LogFormat == "json" {
+		setJSONLogging()
 	}
 
-	b, err := bus.New(
-		*configuration.RPCURL,
-		*configuration.RPCUser,
-		*configuration.RPCPassword,
-		configuration.TorProxy,
-		configuration.NoTLS,
-		unloadWallet,
-	)
+	b, err := bus.New(configuration, unloadWallet)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error": err,
 		}).Fatal("Failed to initialize Bus")
-		return nil
+		return nil, nil
 	}
 
 	log.WithFields(log.Fields{
 		"chain":       b.Chain,
+		"wallet":      b.WalletName,
 		"pruned":      b.Pruned,
 		"txindex":     b.TxIndex,
 		"blockFilter": b.BlockFilter,
@@ -181,7 +343,11 @@ func startup(unloadWallet bool, circulationCheck bool, forceImportDesc bool) *sv
 
 	fortunes.Fortune()
 
-	s.Bus.Worker(configuration, circulationCheck, forceImportDesc)
+	s.Bus.Worker(configuration, circulationCheck, circulationCheckHeight, forceImportDesc, deferredRescan)
+
+	if configuration.APIToken == "" {
+		log.Warn("No api_token configured, control endpoints are open to anyone who can reach SatStack")
+	}
 
-	return s
+	return s, configuration
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -78,9 +79,38 @@ func LoadRescanConf() (*ConfigurationRescan, error) {
 		return nil, fmt.Errorf("%s: %w", ErrMalformed, err)
 	}
 
+	if err := configuration.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrValidation, err)
+	}
+
 	return configuration, nil
 }
 
+// BackupRescanConf renames the rescan checkpoint file, so that it is ignored
+// on subsequent startups but remains available for debugging. It returns
+// the path of the backup.
+func BackupRescanConf() (string, error) {
+	paths, err := configRescanLookupPaths()
+	if err != nil {
+		return "", err
+	}
+
+	for _, maybePath := range paths {
+		if !fileExists(maybePath) {
+			continue
+		}
+
+		backupPath := fmt.Sprintf("%s.corrupt-%d", maybePath, time.Now().Unix())
+		if err := os.Rename(maybePath, backupPath); err != nil {
+			return "", err
+		}
+
+		return backupPath, nil
+	}
+
+	return "", ErrConfigFileNotFound
+}
+
 // fileExists checks if a file exists and is not a directory before we
 // try using it to prevent further errors.
 func fileExists(filename string) bool {
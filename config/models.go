@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -11,8 +13,56 @@ import (
 type Account struct {
 	External *string `json:"external"` // output descriptor at external path
 	Internal *string `json:"internal"` // output descriptor at internal path
-	Depth    *int    `json:"depth"`    // (?) Number of addresses to import
-	Birthday *date   `json:"birthday"` // (?) Earliest known creation date (YYYY/MM/DD)
+
+	// Alternatively to External and Internal, an account can be specified by
+	// its extended public key, and the descriptors are generated by SatStack.
+	XPub        *string `json:"xpub"`        // (?) xpub/ypub/zpub (or testnet equivalent) of the account
+	Scheme      string  `json:"scheme"`      // (?) Script scheme: legacy, segwit, native_segwit or taproot
+	Fingerprint *string `json:"fingerprint"` // (?) Master key fingerprint, to include the key origin
+
+	Depth    *int   `json:"depth"`    // (?) Number of addresses to import
+	Birthday *date  `json:"birthday"` // (?) Earliest known creation date (YYYY/MM/DD), block height, "now" or "genesis"
+	Label    string `json:"label"`    // (?) Wallet label of the receive addresses of the account
+}
+
+// key identifies an account by what it watches, regardless of its label,
+// depth or birthday.
+func (a Account) key() string {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+
+		return *s
+	}
+
+	return strings.Join([]string{deref(a.External), deref(a.Internal), deref(a.XPub), a.Scheme}, "|")
+}
+
+// DiffAccounts returns the accounts of current that are not in previous, and
+// those of previous that are no longer in current.
+func DiffAccounts(previous []Account, current []Account) (added []Account, removed []Account) {
+	previousKeys := make(map[string]bool, len(previous))
+	for _, account := range previous {
+		previousKeys[account.key()] = true
+	}
+
+	currentKeys := make(map[string]bool, len(current))
+	for _, account := range current {
+		currentKeys[account.key()] = true
+
+		if !previousKeys[account.key()] {
+			added = append(added, account)
+		}
+	}
+
+	for _, account := range previous {
+		if !currentKeys[account.key()] {
+			removed = append(removed, account)
+		}
+	}
+
+	return added, removed
 }
 
 // Configuration is a struct to model the JSON configuration
@@ -20,12 +70,35 @@ type Account struct {
 //
 // Fields marked as (?) are optional.
 type Configuration struct {
-	RPCURL      *string   `json:"rpcurl"`
-	RPCUser     *string   `json:"rpcuser"`
-	RPCPassword *string   `json:"rpcpass"`
-	TorProxy    string    `json:"torproxy"`
-	NoTLS       bool      `json:"notls"`
-	Accounts    []Account `json:"accounts"`
+	RPCURL                   *string   `json:"rpcurl"`
+	RPCUser                  *string   `json:"rpcuser"`
+	RPCPassword              *string   `json:"rpcpass"`
+	RPCCookiePath            string    `json:"rpc_cookie_path"` // (?) Path to the bitcoind .cookie file, used instead of rpcuser/rpcpass
+	TorProxy                 string    `json:"torproxy"`
+	NoTLS                    bool      `json:"notls"`
+	RPCTLS                   *bool     `json:"rpc_tls"`                      // (?) Use TLS for RPC, takes precedence over notls
+	RPCCACertPath            string    `json:"rpc_ca_cert_path"`             // (?) PEM CA certificate to verify the RPC server with
+	RPCTLSInsecureSkipVerify bool      `json:"rpc_tls_insecure_skip_verify"` // (?) Skip verification of the RPC server certificate
+	Wallet                   string    `json:"wallet"`                       // (?) Name of the bitcoind wallet, defaults to "satstack"
+	NoCreateWallet           bool      `json:"nocreatewallet"`               // (?) Do not create the wallet if missing, for wallets managed externally
+	Currency                 string    `json:"currency"`                     // (?) Expected currency of the node: "btc" or "btc_testnet"
+	SignetChallenge          string    `json:"signetchallenge"`              // (?) Hex challenge of a custom signet, as in bitcoind -signetchallenge
+	LogFormat                string    `json:"logformat"`                    // (?) Log output format: "text" (default) or "json"
+	RescanChunkSize          int64     `json:"rescanchunksize"`              // (?) Number of blocks to rescan at once, defaults to the whole range
+	AllowFullRescan          bool      `json:"allowfullrescan"`              // (?) Allow "genesis" birthdays, which rescan the entire blockchain
+	APIToken                 string    `json:"api_token"`                    // (?) Token required to call the control endpoints
+	APITokenExplorer         bool      `json:"api_token_explorer"`           // (?) Also require the api_token for the explorer endpoints
+	Accounts                 []Account `json:"accounts"`
+}
+
+// TLSEnabled reports whether RPC connections must use TLS. The rpc_tls
+// option, if set, takes precedence over notls.
+func (c Configuration) TLSEnabled() bool {
+	if c.RPCTLS != nil {
+		return *c.RPCTLS
+	}
+
+	return !c.NoTLS
 }
 
 // Type for saving the Rescan time to avoid scanning the wallet
@@ -34,16 +107,57 @@ type ConfigurationRescan struct {
 	LastSyncTime    string `json:"last_synctime"`
 	TimeStamp       string `json:"timestamp"`
 	LastBlock       int64  `json:"last_block"`
+	RescanStart     *int64 `json:"rescan_start,omitempty"` // First block of the rescanned range ending at LastBlock, if known
+	RescanTime      *int64 `json:"rescan_time,omitempty"`  // Median time past of the tip when the rescan of the range began, set once it completed
 	SatstackVersion string `json:"satstack_version"`
 }
 
+// date models the birthday of an account. It can be specified either as a
+// date in YYYY/MM/DD format, a block height, or the special values "now" and
+// "genesis".
 type date struct {
 	time.Time
+
+	// Height is set if the birthday was specified as a block height.
+	Height *int64
+
+	// Now is set if the birthday was specified as "now", meaning the time
+	// of the current chain tip.
+	Now bool
+
+	// Genesis is set if the birthday was specified as "genesis", meaning
+	// that the entire blockchain must be scanned.
+	Genesis bool
+}
+
+// IsTime reports whether the birthday was specified as a date.
+func (d *date) IsTime() bool {
+	return d.Height == nil && !d.Now && !d.Genesis
 }
 
 func (d *date) UnmarshalJSON(input []byte) error {
 	strInput := string(input)
 	strInput = strings.Trim(strInput, `"`)
+
+	if strings.EqualFold(strInput, "now") {
+		d.Now = true
+		return nil
+	}
+
+	if strings.EqualFold(strInput, "genesis") {
+		d.Genesis = true
+		return nil
+	}
+
+	if height, err := strconv.ParseInt(strInput, 10, 64); err == nil {
+		if height < 0 {
+			return fmt.Errorf("negative birthday height: %d", height)
+		}
+
+		d.Height = &height
+		return nil
+	}
+
 	newTime, err := time.Parse("2006/01/02", strInput)
 	if err != nil {
 		return err
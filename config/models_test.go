@@ -0,0 +1,52 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffAccounts(t *testing.T) {
+	str := func(s string) *string { return &s }
+	depth := 500
+
+	a := Account{External: str("wpkh(tpubA/0/*)"), Internal: str("wpkh(tpubA/1/*)"), Label: "a"}
+	b := Account{External: str("wpkh(tpubB/0/*)"), Internal: str("wpkh(tpubB/1/*)"), Label: "b"}
+	xpub := Account{XPub: str("tpubC"), Scheme: "native_segwit"}
+
+	// Accounts are identified by what they watch, regardless of their
+	// label, depth or birthday.
+	relabelled := Account{External: str("wpkh(tpubA/0/*)"), Internal: str("wpkh(tpubA/1/*)"), Label: "savings", Depth: &depth}
+	rescheme := Account{XPub: str("tpubC"), Scheme: "taproot"}
+
+	tests := []struct {
+		name     string
+		previous []Account
+		current  []Account
+		added    []Account
+		removed  []Account
+	}{
+		{name: "initial load", current: []Account{a, b}, added: []Account{a, b}},
+		{name: "unchanged", previous: []Account{a, b}, current: []Account{a, b}},
+		{name: "reordered", previous: []Account{a, b}, current: []Account{b, a}},
+		{name: "added", previous: []Account{a}, current: []Account{a, b, xpub}, added: []Account{b, xpub}},
+		{name: "removed", previous: []Account{a, b, xpub}, current: []Account{b}, removed: []Account{a, xpub}},
+		{name: "replaced", previous: []Account{a}, current: []Account{b}, added: []Account{b}, removed: []Account{a}},
+		{name: "relabelled", previous: []Account{a}, current: []Account{relabelled}},
+		{name: "scheme changed", previous: []Account{xpub}, current: []Account{rescheme}, added: []Account{rescheme}, removed: []Account{xpub}},
+		{name: "all removed", previous: []Account{a, b}, removed: []Account{a, b}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := DiffAccounts(tt.previous, tt.current)
+
+			if !reflect.DeepEqual(added, tt.added) {
+				t.Errorf("DiffAccounts() added = %v, want %v", added, tt.added)
+			}
+
+			if !reflect.DeepEqual(removed, tt.removed) {
+				t.Errorf("DiffAccounts() removed = %v, want %v", removed, tt.removed)
+			}
+		})
+	}
+}
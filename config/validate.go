@@ -1,11 +1,22 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// keyOriginRegexp matches the key origins of a descriptor, capturing their
+// contents without the brackets.
+var keyOriginRegexp = regexp.MustCompile(`\[([^\]]*)\]`)
+
+// fingerprintRegexp matches a BIP32 key fingerprint, as 8 hex characters.
+var fingerprintRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}$`)
+
 // validate checks for the validity of the JSON configuration loaded in
 // Configuration struct.
 //
@@ -16,23 +27,75 @@ func (c Configuration) validate() error {
 		return err
 	}
 
-	if err := validateStringField("rpcuser", c.RPCUser); err != nil {
-		return err
+	// Static credentials are only required if no cookie file is configured.
+	if c.RPCCookiePath == "" {
+		if err := validateStringField("rpcuser", c.RPCUser); err != nil {
+			return err
+		}
+
+		if err := validateStringField("rpcpass", c.RPCPassword); err != nil {
+			return err
+		}
 	}
 
-	if err := validateStringField("rpcpass", c.RPCPassword); err != nil {
-		return err
+	if !c.TLSEnabled() && (c.RPCCACertPath != "" || c.RPCTLSInsecureSkipVerify) {
+		return fmt.Errorf("rpc_ca_cert_path and rpc_tls_insecure_skip_verify require TLS to be enabled")
 	}
 
-	for _, account := range c.Accounts {
-		if err := validateStringField("external", account.External); err != nil {
-			return err
+	if c.RescanChunkSize < 0 {
+		return fmt.Errorf("invalid rescanchunksize %d", c.RescanChunkSize)
+	}
+
+	if c.APITokenExplorer && c.APIToken == "" {
+		return fmt.Errorf("api_token_explorer requires api_token to be set")
+	}
+
+	if c.SignetChallenge != "" {
+		if _, err := hex.DecodeString(c.SignetChallenge); err != nil {
+			return fmt.Errorf("invalid signetchallenge: %w", err)
 		}
-		if err := validateStringField("internal", account.Internal); err != nil {
+	}
+
+	switch c.LogFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid logformat '%s'", c.LogFormat)
+	}
+
+	labels := make(map[string]bool, len(c.Accounts))
+
+	for i, account := range c.Accounts {
+		if err := account.validateKeys(); err != nil {
 			return err
 		}
 
-		if account.Birthday != nil && account.Birthday.Before(BIP0039Genesis) {
+		// Labels identify accounts, for ex to reimport them.
+		if account.Label != "" {
+			if labels[account.Label] {
+				return fmt.Errorf("account #%d: duplicate label '%s'", i, account.Label)
+			}
+
+			labels[account.Label] = true
+		}
+
+		for _, desc := range []*string{account.External, account.Internal} {
+			if desc == nil {
+				continue
+			}
+
+			if err := validateKeyOrigins(i, *desc); err != nil {
+				return err
+			}
+		}
+
+		// A full-chain rescan can take several hours, so it must be opted
+		// into explicitly.
+		if account.Birthday != nil && account.Birthday.Genesis && !c.AllowFullRescan {
+			return fmt.Errorf("account #%d: birthday 'genesis' requires allowfullrescan", i)
+		}
+
+		if account.Birthday != nil && account.Birthday.IsTime() &&
+			account.Birthday.Before(BIP0039Genesis) {
 			log.WithFields(log.Fields{
 				"descriptor": account.External,
 				"birthday":   account.Birthday,
@@ -43,6 +106,105 @@ func (c Configuration) validate() error {
 	return nil
 }
 
+// validate checks for the validity of the rescan checkpoint loaded in
+// ConfigurationRescan struct, to avoid trusting a corrupt file.
+func (c ConfigurationRescan) validate() error {
+	if c.LastBlock < 0 {
+		return fmt.Errorf("negative last_block %d", c.LastBlock)
+	}
+
+	if _, err := strconv.ParseInt(c.TimeStamp, 10, 64); err != nil {
+		return fmt.Errorf("invalid timestamp '%s'", c.TimeStamp)
+	}
+
+	if c.RescanStart != nil && (*c.RescanStart < 0 || *c.RescanStart > c.LastBlock) {
+		return fmt.Errorf("rescan_start %d out of range [0, %d]", *c.RescanStart, c.LastBlock)
+	}
+
+	if c.RescanTime != nil && (*c.RescanTime < 0 || c.RescanStart == nil) {
+		return fmt.Errorf("invalid rescan_time %d", *c.RescanTime)
+	}
+
+	return nil
+}
+
+// validateKeys checks that an account is specified either by its external and
+// internal descriptors, or by an extended public key and a script scheme.
+func (a Account) validateKeys() error {
+	if a.XPub == nil {
+		if err := validateStringField("external", a.External); err != nil {
+			return err
+		}
+
+		return validateStringField("internal", a.Internal)
+	}
+
+	if a.External != nil || a.Internal != nil {
+		return fmt.Errorf("xpub cannot be combined with external/internal descriptors")
+	}
+
+	switch a.Scheme {
+	case "legacy", "segwit", "native_segwit", "taproot":
+	default:
+		return fmt.Errorf("invalid scheme '%s' for xpub %s", a.Scheme, *a.XPub)
+	}
+
+	if a.Fingerprint != nil && !fingerprintRegexp.MatchString(*a.Fingerprint) {
+		return fmt.Errorf("invalid fingerprint '%s'", *a.Fingerprint)
+	}
+
+	return nil
+}
+
+// KeyOriginError indicates that the key origin of a descriptor, in the form
+// [fingerprint/path], is malformed.
+type KeyOriginError struct {
+	Account    int    // Index of the offending account in the config
+	Descriptor string // Offending descriptor
+	Origin     string // Offending key origin, without brackets
+	Reason     string
+}
+
+func (e *KeyOriginError) Error() string {
+	return fmt.Sprintf("account #%d: invalid key origin [%s] in %s: %s",
+		e.Account, e.Origin, e.Descriptor, e.Reason)
+}
+
+// validateKeyOrigins checks that every key origin of a descriptor has a
+// fingerprint of 8 hex characters, followed by a well-formed derivation path.
+func validateKeyOrigins(account int, descriptor string) error {
+	for _, match := range keyOriginRegexp.FindAllStringSubmatch(descriptor, -1) {
+		origin := match[1]
+
+		originErr := func(reason string) error {
+			return &KeyOriginError{
+				Account:    account,
+				Descriptor: descriptor,
+				Origin:     origin,
+				Reason:     reason,
+			}
+		}
+
+		steps := strings.Split(origin, "/")
+		if !fingerprintRegexp.MatchString(steps[0]) {
+			return originErr(fmt.Sprintf("fingerprint '%s' is not 8 hex characters", steps[0]))
+		}
+
+		for _, step := range steps[1:] {
+			index := strings.TrimRight(step, "'hH")
+			if len(step)-len(index) > 1 {
+				return originErr(fmt.Sprintf("malformed path step '%s'", step))
+			}
+
+			if _, err := strconv.ParseUint(index, 10, 31); err != nil {
+				return originErr(fmt.Sprintf("malformed path step '%s'", step))
+			}
+		}
+	}
+
+	return nil
+}
+
 func validateStringField(key string, value *string) error {
 	if value == nil {
 		return fmt.Errorf("%s: %s", ErrMissingKey, key)
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -47,14 +48,57 @@ func WriteRescanConf(data *ConfigurationRescan) error {
 
 	// Writing to file
 
-	file, _ := json.MarshalIndent(*data, "", " ")
-	ferr := os.WriteFile(configPath, file, 0644)
-	if ferr != nil {
-		log.Error("Error savng last timestamp to file %s: %s", configPath, ferr)
+	file, err := json.MarshalIndent(*data, "", " ")
+	if err != nil {
 		return err
 	}
 
+	ferr := writeFileAtomic(configPath, file, 0644)
+	if ferr != nil {
+		log.Errorf("Error savng last timestamp to file %s: %s", configPath, ferr)
+		return ferr
+	}
+
 	log.WithField("path", configPath).Info("RescanConfigFile successfully saved")
 
 	return nil
 }
+
+// rename is os.Rename, replaced in tests to simulate a crash before the
+// temporary file replaces the destination.
+var rename = os.Rename
+
+// writeFileAtomic writes data to a temporary file in the directory of path,
+// and renames it to path once fully written. This ensures that path always
+// holds either the previous or the new contents, even if the process is
+// killed mid-write.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	// Clean up the temporary file on failure. After a successful rename,
+	// this is a no-op.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return rename(tmp.Name(), path)
+}
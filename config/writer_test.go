@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lss_rescan.json")
+
+	if err := writeFileAtomic(path, []byte(`{"last_block":1}`), 0644); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	if err := writeFileAtomic(path, []byte(`{"last_block":2}`), 0644); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	assertContents(t, path, `{"last_block":2}`)
+	assertNoTempFiles(t, path)
+}
+
+func TestWriteFileAtomicCrashBeforeRename(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lss_rescan.json")
+
+	if err := writeFileAtomic(path, []byte(`{"last_block":1}`), 0644); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	errCrash := errors.New("crash")
+
+	// Fail once the new checkpoint is fully written to the temporary file,
+	// but before it replaces the previous one.
+	rename = func(oldpath, newpath string) error {
+		assertContents(t, oldpath, `{"last_block":2}`)
+		return errCrash
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	if err := writeFileAtomic(path, []byte(`{"last_block":2}`), 0644); !errors.Is(err, errCrash) {
+		t.Fatalf("writeFileAtomic() error = %v, want %v", err, errCrash)
+	}
+
+	assertContents(t, path, `{"last_block":1}`)
+	assertNoTempFiles(t, path)
+}
+
+func assertContents(t *testing.T, path string, want string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read %s: %v", path, err)
+	}
+
+	if string(data) != want {
+		t.Errorf("%s contents = %s, want %s", path, data, want)
+	}
+}
+
+func assertNoTempFiles(t *testing.T, path string) {
+	t.Helper()
+
+	matches, err := filepath.Glob(path + ".tmp-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
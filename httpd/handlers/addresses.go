@@ -1,17 +1,28 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/ledgerhq/satstack/bus"
 	"github.com/ledgerhq/satstack/httpd/svc"
+	"github.com/ledgerhq/satstack/types"
 	"github.com/ledgerhq/satstack/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// GetAddresses is a gin handler (factory) to get the transactions of a
+// comma-separated list of addresses.
+//
+// The history is paginated if the limit or cursor query parameter is set.
+// Pages are ordered by confirmation height descending, then by transaction
+// ID, and the next query parameter of the response is the cursor of the next
+// page, if any.
 func GetAddresses(s svc.AddressesService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		param := ctx.Param("addresses")
@@ -32,12 +43,24 @@ func GetAddresses(s svc.AddressesService) gin.HandlerFunc {
 			blockHeight = &i32
 		}
 
-		addresses, err := s.GetAddresses(addressList, blockHash, blockHeight)
+		page, err := parseHistoryPage(ctx)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		addresses, err := s.GetAddresses(ctx.Request.Context(), addressList, blockHash, blockHeight, page)
 		if err != nil {
 			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
 			return
 		}
 
+		// Paginated transactions are already in history order.
+		if page != nil {
+			ctx.JSON(http.StatusOK, addresses)
+			return
+		}
+
 		// FIXME: libcore relies on the order of the transactions, in order to
 		//        correctly compute operation values (aka amounts). This order
 		//        appears to be based on the ReceivedAt field, although it is
@@ -60,3 +83,174 @@ func GetAddresses(s svc.AddressesService) gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, addresses)
 	}
 }
+
+// GetUTXOs is a gin handler (factory) to list the UTXOs of a comma-separated
+// list of addresses.
+//
+// The optional min_conf query parameter sets the minimum number of
+// confirmations of the returned UTXOs. It defaults to 0, which includes
+// unconfirmed UTXOs.
+func GetUTXOs(s svc.AddressesService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		addressList := strings.Split(ctx.Param("addresses"), ",")
+
+		minConf, err := parseMinConf(ctx)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		utxos, err := s.GetUTXOs(addressList, minConf)
+		if err != nil {
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, utxos)
+	}
+}
+
+// GetBalance is a gin handler (factory) to get the aggregate balance of a
+// comma-separated list of addresses.
+//
+// See GetUTXOs for the semantics of the min_conf query parameter.
+func GetBalance(s svc.AddressesService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		addressList := strings.Split(ctx.Param("addresses"), ",")
+
+		minConf, err := parseMinConf(ctx)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		balance, err := s.GetBalance(addressList, minConf)
+		if err != nil {
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, balance)
+	}
+}
+
+// GetBalances is a gin handler (factory) to get the balances of a batch of
+// addresses, given in the JSON body, keyed by address.
+//
+// The optional min_conf field has the same semantics as the min_conf query
+// parameter of GetUTXOs.
+func GetBalances(s svc.AddressesService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Addresses []string `json:"addresses" binding:"required"`
+			MinConf   int      `json:"min_conf"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		if request.MinConf < 0 {
+			ctx.String(http.StatusBadRequest, "text/plain",
+				[]byte(fmt.Sprintf("invalid min_conf %d", request.MinConf)))
+			return
+		}
+
+		balances, err := s.GetBalances(request.Addresses, request.MinConf)
+		switch {
+		case errors.Is(err, bus.ErrBatchTooLarge):
+			ctx.String(http.StatusRequestEntityTooLarge, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrInvalidAddress):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			ctx.JSON(http.StatusOK, balances)
+		}
+	}
+}
+
+// GetXPubBalance is a gin handler (factory) to get the aggregate balance of
+// the account of an extended public key, whose descriptors are imported in
+// the wallet.
+//
+// Supported query parameters:
+//   - scheme:    legacy, segwit, native_segwit (default) or taproot
+//   - gap_limit: number of consecutive unused addresses to stop at (default: 20)
+//   - min_conf:  see GetUTXOs
+func GetXPubBalance(s svc.AddressesService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scheme := ctx.DefaultQuery("scheme", "native_segwit")
+
+		gapLimit, err := strconv.Atoi(ctx.DefaultQuery("gap_limit", strconv.Itoa(bus.DefaultGapLimit)))
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain",
+				[]byte(fmt.Sprintf("invalid gap_limit '%s'", ctx.Query("gap_limit"))))
+			return
+		}
+
+		minConf, err := parseMinConf(ctx)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		balance, err := s.GetXPubBalance(ctx.Param("xpub"), scheme, gapLimit, minConf)
+		switch {
+		case errors.Is(err, bus.ErrDescriptorNotImported):
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrInvalidXPub), errors.Is(err, bus.ErrInvalidGapLimit):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			ctx.JSON(http.StatusOK, balance)
+		}
+	}
+}
+
+func parseMinConf(ctx *gin.Context) (int, error) {
+	minConfQuery := ctx.Query("min_conf")
+	if minConfQuery == "" {
+		return 0, nil
+	}
+
+	minConf, err := strconv.Atoi(minConfQuery)
+	if err != nil || minConf < 0 {
+		return 0, fmt.Errorf("invalid min_conf '%s'", minConfQuery)
+	}
+
+	return minConf, nil
+}
+
+// parseHistoryPage returns the page of history selected by the limit and
+// cursor query parameters, or nil if neither is set.
+func parseHistoryPage(ctx *gin.Context) (*types.HistoryPage, error) {
+	limitQuery, cursorQuery := ctx.Query("limit"), ctx.Query("cursor")
+	if limitQuery == "" && cursorQuery == "" {
+		return nil, nil
+	}
+
+	page := &types.HistoryPage{Limit: defaultPageLimit}
+
+	if limitQuery != "" {
+		limit, err := strconv.Atoi(limitQuery)
+		if err != nil || limit <= 0 || limit > maxPageLimit {
+			return nil, fmt.Errorf("invalid limit '%s' (max %d)", limitQuery, maxPageLimit)
+		}
+
+		page.Limit = limit
+	}
+
+	if cursorQuery != "" {
+		cursor, err := types.ParseHistoryCursor(cursorQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		page.After = cursor
+	}
+
+	return page, nil
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/ledgerhq/satstack/bus"
 	"github.com/ledgerhq/satstack/httpd/svc"
 	"github.com/ledgerhq/satstack/types"
 
@@ -17,16 +21,37 @@ import (
 //
 // Except for the case where the block reference is "current", the response is
 // a list of 1 element.
+//
+// On pruned nodes, blocks below the prune height are reported with a 410 Gone
+// status, to distinguish them from unknown blocks.
 func GetBlock(s svc.BlocksService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		blockRef := ctx.Param("block")
 
 		block, err := s.GetBlock(blockRef)
+		if errors.Is(err, bus.ErrBlockPruned) {
+			ctx.String(http.StatusGone, "text/plain", []byte(err.Error()))
+			return
+		}
+
 		if err != nil {
 			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
 			return
 		}
 
+		// Blocks referenced by hash are immutable, while the block at a given
+		// height may change with a reorg.
+		if isBlockHashReference(blockRef) {
+			ctx.Header("Cache-Control", immutableCacheControl)
+		} else {
+			noCache(ctx)
+		}
+
+		modified, _ := time.Parse(time.RFC3339, block.Time)
+		if notModified(ctx, block.Hash, modified) {
+			return
+		}
+
 		switch blockRef {
 		case "current":
 			ctx.JSON(http.StatusOK, block)
@@ -35,3 +60,126 @@ func GetBlock(s svc.BlocksService) gin.HandlerFunc {
 		}
 	}
 }
+
+// GetLatestBlock gets a summary of the current tip block, with its height,
+// hash, time, and number of transactions.
+func GetLatestBlock(s svc.BlocksService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		block, err := s.GetLatestBlock()
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+
+		modified, _ := time.Parse(time.RFC3339, block.Time)
+		if notModified(ctx, block.Hash, modified) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, block)
+	}
+}
+
+// GetRawBlock gets the hex-encoded serialized block, referenced in the same
+// way as GetBlock, for clients that parse blocks themselves.
+func GetRawBlock(s svc.BlocksService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		blockRef := ctx.Param("block")
+
+		blockHex, err := s.GetRawBlock(blockRef)
+		switch {
+		case errors.Is(err, bus.ErrBlockPruned):
+			ctx.String(http.StatusGone, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		if isBlockHashReference(blockRef) {
+			ctx.Header("Cache-Control", immutableCacheControl)
+		} else {
+			noCache(ctx)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"hex": blockHex,
+		})
+	}
+}
+
+// GetBlockStats gets the statistics of a block, referenced in the same way as
+// GetBlock, such as its fees and the share of segwit transactions.
+func GetBlockStats(s svc.BlocksService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		blockRef := ctx.Param("block")
+
+		stats, err := s.GetBlockStats(blockRef)
+		switch {
+		case errors.Is(err, bus.ErrBlockPruned):
+			ctx.String(http.StatusGone, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		if isBlockHashReference(blockRef) {
+			ctx.Header("Cache-Control", immutableCacheControl)
+		} else {
+			noCache(ctx)
+		}
+
+		modified, _ := time.Parse(time.RFC3339, stats.Time)
+		if notModified(ctx, stats.Hash, modified) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, stats)
+	}
+}
+
+// GetBlockFilter gets the BIP158 compact block filter of a block, referenced
+// in the same way as GetBlock.
+//
+// If the block filter index of the node is disabled, a 501 Not Implemented
+// status is returned.
+func GetBlockFilter(s svc.BlocksService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		blockRef := ctx.Param("block")
+
+		filter, err := s.GetBlockFilter(blockRef)
+		switch {
+		case errors.Is(err, bus.ErrBlockFilterDisabled):
+			ctx.String(http.StatusNotImplemented, "text/plain", []byte(err.Error()))
+			return
+		case errors.Is(err, bus.ErrBlockPruned):
+			ctx.String(http.StatusGone, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		if isBlockHashReference(blockRef) {
+			ctx.Header("Cache-Control", immutableCacheControl)
+		} else {
+			noCache(ctx)
+		}
+
+		// The filter header commits to the filter, and all previous ones.
+		if notModified(ctx, filter.Header, time.Time{}) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, filter)
+	}
+}
+
+// isBlockHashReference reports whether a block reference is a block hash,
+// as opposed to a height or "current".
+func isBlockHashReference(ref string) bool {
+	return strings.HasPrefix(ref, "0x") || len(ref) == 64
+}
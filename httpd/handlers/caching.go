@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// immutableCacheControl is the Cache-Control header of responses that never
+// change for a given URL, for ex a block referenced by hash.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// notModified sets the ETag and Last-Modified (unless zero) headers of a
+// response, and reports whether the copy cached by the client is still
+// fresh. In such cases, a 304 Not Modified status is sent, and the caller
+// must not write a body.
+//
+// As per RFC 7232, If-None-Match takes precedence over If-Modified-Since.
+func notModified(ctx *gin.Context, etag string, modified time.Time) bool {
+	etag = `"` + etag + `"`
+	ctx.Header("ETag", etag)
+
+	if !modified.IsZero() {
+		ctx.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if match := ctx.GetHeader("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				ctx.Status(http.StatusNotModified)
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if since := ctx.GetHeader("If-Modified-Since"); since != "" && !modified.IsZero() {
+		t, err := http.ParseTime(since)
+		if err == nil && !modified.Truncate(time.Second).After(t) {
+			ctx.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// noCache requires clients to revalidate the response before reusing it,
+// for data that changes over time, like the chain tip or the mempool.
+func noCache(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-cache")
+}
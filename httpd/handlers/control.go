@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/ledgerhq/satstack/bus"
 	"github.com/ledgerhq/satstack/config"
 	"github.com/ledgerhq/satstack/httpd/svc"
+	"github.com/ledgerhq/satstack/types"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/gin-gonic/gin"
@@ -22,12 +29,113 @@ func ImportAccounts(s svc.ControlService) gin.HandlerFunc {
 			return
 		}
 
+		// In dry-run mode, only report what would be imported.
+		if ctx.Query("dry_run") == "true" {
+			preview, err := s.PreviewImport(request.Accounts)
+			if err != nil {
+				log.WithField("error", err).Error("Failed to preview import")
+				ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+				return
+			}
+
+			ctx.JSON(http.StatusOK, preview)
+			return
+		}
+
 		s.ImportAccounts(request.Accounts)
 
 		ctx.JSON(http.StatusOK, gin.H{"Status": "OK"})
 	}
 }
 
+// GetDescriptorInfo returns how the node interprets a descriptor, to help
+// debugging account configs.
+func GetDescriptorInfo(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Descriptor string `json:"descriptor" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			log.Error("Failed to bind JSON request")
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		info, err := s.GetDescriptorInfo(request.Descriptor)
+		if err != nil {
+			log.WithField("error", err).Error("Failed to get descriptor info")
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, info)
+	}
+}
+
+// DeriveAddressAt returns the address at an index of a ranged descriptor,
+// so that clients can check that a receive address matches the one shown by
+// the device.
+func DeriveAddressAt(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Descriptor string `json:"descriptor" binding:"required"`
+			Index      *int   `json:"index" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		address, err := s.DeriveAddressAt(request.Descriptor, *request.Index)
+		switch {
+		case errors.Is(err, bus.ErrInvalidDescriptor),
+			errors.Is(err, bus.ErrDescriptorNotRanged),
+			errors.Is(err, bus.ErrIndexOutOfRange):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			log.WithField("error", err).Error("Failed to derive address")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, address)
+	}
+}
+
+// ScanTxOutSet returns the UTXOs and total amount of the given descriptors,
+// by scanning the UTXO set of the node. Unlike ImportAccounts, nothing is
+// imported into the wallet.
+//
+// Only one scan can run at a time, so concurrent requests are rejected with
+// a 409 Conflict status.
+func ScanTxOutSet(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Descriptors []string `json:"descriptors" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			log.Error("Failed to bind JSON request")
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		result, err := s.ScanTxOutSet(request.Descriptors)
+		switch {
+		case errors.Is(err, bus.ErrScanInProgress):
+			ctx.String(http.StatusConflict, "text/plain", []byte(err.Error()))
+		case err != nil:
+			log.WithField("error", err).Error("Failed to scan UTXO set")
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		default:
+			ctx.JSON(http.StatusOK, result)
+		}
+	}
+}
+
 func HasDescriptor(s svc.ControlService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var request struct {
@@ -52,3 +160,303 @@ func HasDescriptor(s svc.ControlService) gin.HandlerFunc {
 		})
 	}
 }
+
+// AbortRescan stops the wallet rescan in progress. The endpoint is
+// idempotent: if no rescan is running, it responds with 200 and does nothing.
+func AbortRescan(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		fields := log.WithContext(ctx.Request.Context()).WithFields(log.Fields{
+			"clientIP":  ctx.ClientIP(),
+			"userAgent": ctx.Request.UserAgent(),
+		})
+
+		fields.Info("Wallet rescan abort requested")
+
+		aborted, err := s.AbortRescan()
+		if err != nil {
+			fields.WithField("error", err).Error("Failed to abort rescan")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		message := "rescan aborted"
+		if !aborted {
+			message = "no rescan in progress"
+		}
+
+		fields.WithField("aborted", aborted).Info("Wallet rescan abort handled")
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"aborted": aborted,
+			"message": message,
+		})
+	}
+}
+
+// ReimportAccount reimports the descriptors of the configured account with
+// the label given by the account query parameter, and rescans the wallet from
+// its birthday only. This is useful when the state of a single account is
+// suspect, without rescanning all of them.
+//
+// The reimport runs in the background, so the endpoint responds with a 202
+// Accepted status.
+func ReimportAccount(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		label := ctx.Query("account")
+
+		fields := log.WithContext(ctx.Request.Context()).WithFields(log.Fields{
+			"clientIP": ctx.ClientIP(),
+			"label":    label,
+		})
+
+		err := s.ReimportAccount(label)
+		switch {
+		case errors.Is(err, bus.ErrAccountNotFound):
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		case errors.Is(err, bus.ErrReimportInProgress):
+			ctx.String(http.StatusConflict, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		fields.Info("Account reimport started")
+
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"account": label,
+			"message": "reimport started",
+		})
+	}
+}
+
+// GetRescanCheckpoint returns the last block that the wallet was successfully
+// rescanned up to.
+func GetRescanCheckpoint(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		checkpoint, err := s.GetRescanCheckpoint()
+		if err != nil {
+			log.WithField("error", err).Error("Failed to get rescan checkpoint")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, checkpoint)
+	}
+}
+
+// RescanNeeded returns the descriptors of the configured accounts that the
+// wallet may not have scanned completely, so that operators can tell whether
+// a rescan is actually required.
+func RescanNeeded(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		descriptors, err := s.RescanNeeded()
+		switch {
+		case errors.Is(err, bus.ErrDescriptorWalletRequired):
+			ctx.String(http.StatusNotImplemented, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, gin.H{
+			"rescan_needed": len(descriptors) > 0,
+			"descriptors":   descriptors,
+		})
+	}
+}
+
+// SetLogLevel changes the log level of SatStack without a restart, for ex to
+// capture debug logs during an incident. The body is in the form {"level":
+// "debug"}, and the response reports the previous level.
+func SetLogLevel(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Level string `json:"level" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		previous, err := s.SetLogLevel(request.Level)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		log.WithContext(ctx.Request.Context()).WithFields(log.Fields{
+			"clientIP": ctx.ClientIP(),
+			"previous": previous,
+			"level":    request.Level,
+		}).Warn("Log level changed")
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"previous": previous,
+			"level":    request.Level,
+		})
+	}
+}
+
+// defaultPageLimit and maxPageLimit bound the number of items returned by
+// paginated endpoints.
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// GetPeers returns the peers connected to the node, with their sync status.
+// Well-connected nodes can have over a hundred peers, so the response is
+// paginated with the offset and limit query parameters.
+func GetPeers(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		offset, limit, err := parsePagination(ctx)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		peers, err := s.GetPeers(offset, limit)
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, peers)
+	}
+}
+
+// ExportDescriptors returns the descriptors watched by the wallet, without
+// private keys, so that operators can back up exactly what is watched.
+func ExportDescriptors(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		descriptors, err := s.ExportDescriptors()
+		switch {
+		case errors.Is(err, bus.ErrDescriptorWalletRequired):
+			ctx.String(http.StatusNotImplemented, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, descriptors)
+	}
+}
+
+// GetChainTips returns the tips of the block tree known by the node, along
+// with their status, for operators investigating reorgs or stuck chains.
+func GetChainTips(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tips, err := s.GetChainTips()
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, tips)
+	}
+}
+
+// CallRPC is a gin handler (factory) to call allowlisted Bitcoin Core RPC
+// methods, as an escape hatch for functionality not covered by the API.
+//
+// The body is either a single call, in the form {"method": ..., "params":
+// [...]}, or an array of calls. The response mirrors the request, with a
+// result and an error field per call. Every call is logged for auditing.
+func CallRPC(s svc.ControlService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		body, err := ctx.GetRawData()
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		batch := len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '['
+
+		var calls []types.RPCCall
+		if batch {
+			err = json.Unmarshal(body, &calls)
+		} else {
+			calls = make([]types.RPCCall, 1)
+			err = json.Unmarshal(body, &calls[0])
+		}
+
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		methods := make([]string, 0, len(calls))
+		for _, call := range calls {
+			if call.Method == "" {
+				ctx.String(http.StatusBadRequest, "text/plain", []byte("missing RPC method"))
+				return
+			}
+
+			methods = append(methods, call.Method)
+		}
+
+		audit := log.WithContext(ctx.Request.Context()).WithFields(log.Fields{
+			"prefix":   "rpc",
+			"clientIP": ctx.ClientIP(),
+			"methods":  methods,
+		})
+
+		results, err := s.CallRPC(calls)
+		switch {
+		case errors.Is(err, bus.ErrRPCMethodNotAllowed):
+			audit.WithField("error", err).Warn("Rejected RPC passthrough call")
+			ctx.String(http.StatusForbidden, "text/plain", []byte(err.Error()))
+			return
+		case errors.Is(err, bus.ErrBatchTooLarge):
+			ctx.String(http.StatusRequestEntityTooLarge, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			audit.WithField("error", err).Error("Failed RPC passthrough call")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		audit.Info("RPC passthrough call")
+
+		noCache(ctx)
+
+		if !batch {
+			ctx.JSON(http.StatusOK, results[0])
+			return
+		}
+
+		ctx.JSON(http.StatusOK, results)
+	}
+}
+
+func parsePagination(ctx *gin.Context) (int, int, error) {
+	offset, limit := 0, defaultPageLimit
+
+	if offsetQuery := ctx.Query("offset"); offsetQuery != "" {
+		n, err := strconv.Atoi(offsetQuery)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid offset '%s'", offsetQuery)
+		}
+
+		offset = n
+	}
+
+	if limitQuery := ctx.Query("limit"); limitQuery != "" {
+		n, err := strconv.Atoi(limitQuery)
+		if err != nil || n <= 0 || n > maxPageLimit {
+			return 0, 0, fmt.Errorf("invalid limit '%s' (max %d)", limitQuery, maxPageLimit)
+		}
+
+		limit = n
+	}
+
+	return offset, limit, nil
+}
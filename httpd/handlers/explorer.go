@@ -1,22 +1,28 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ledgerhq/satstack/bus"
 	"github.com/ledgerhq/satstack/httpd/svc"
 )
 
+// GetFees is a gin handler (factory) to get fee estimates.
+//
+// Supported query parameters:
+//   - block_count: confirmation target, can be repeated (default: 2, 3, 6)
+//   - mode:        UNSET, ECONOMICAL or CONSERVATIVE (default)
+//   - unit:        sat/kvB (default) or sat/vB
 func GetFees(s svc.ExplorerService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		blockCounts := ctx.QueryArray("block_count")
-		mode := strings.ToUpper(ctx.Param("mode"))
-		if mode == "" || (mode != "UNSET" && mode != "ECONOMICAL" && mode != "CONSERVATIVE") {
-			mode = "CONSERVATIVE"
-		}
+		mode := ctx.Query("mode")
+		unit := ctx.Query("unit")
 
 		var blockCountsIntegers []int64
 		for _, blockCount := range blockCounts {
@@ -29,20 +35,61 @@ func GetFees(s svc.ExplorerService) gin.HandlerFunc {
 			blockCountsIntegers = append(blockCountsIntegers, 2, 3, 6)
 		}
 
-		fees := s.GetFees(blockCountsIntegers, mode)
+		fees, err := s.GetFees(blockCountsIntegers, mode, unit)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
 		ctx.JSON(http.StatusOK, fees)
 	}
 }
 
-func GetHealth(s svc.ExplorerService) gin.HandlerFunc {
+func GetPriorityFee(s svc.ExplorerService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		err := s.GetHealth()
+		fee, err := s.GetPriorityFee()
 		if err != nil {
-			ctx.JSON(http.StatusServiceUnavailable, err)
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"Status": "OK"})
+		ctx.JSON(http.StatusOK, fee)
+	}
+}
+
+// GetDustThreshold is a gin handler (factory) to get the smallest value of
+// an output that the node relays, for the script type given by the
+// script_type query parameter (p2pkh, p2sh, p2wpkh, p2wsh or p2tr).
+func GetDustThreshold(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		threshold, err := s.GetDustThreshold(ctx.Query("script_type"))
+		switch {
+		case errors.Is(err, bus.ErrUnknownScriptType):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			ctx.JSON(http.StatusOK, threshold)
+		}
+	}
+}
+
+// GetHealth is a gin handler (factory) to probe the health of the node, for
+// ex from a load balancer.
+//
+// A node breaching the health thresholds is reported as degraded, with a 503
+// Service Unavailable status, so that it can be pulled out of rotation.
+func GetHealth(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		err := s.GetHealth()
+		switch {
+		case errors.Is(err, bus.ErrHealthDegraded):
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"Status": "DEGRADED", "Reason": err.Error()})
+		case err != nil:
+			ctx.JSON(http.StatusServiceUnavailable, err)
+		default:
+			ctx.JSON(http.StatusOK, gin.H{"Status": "OK"})
+		}
 	}
 }
 
@@ -52,12 +99,116 @@ func GetNetwork(s svc.ExplorerService) gin.HandlerFunc {
 	}
 }
 
+func GetNetworkStats(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		stats, err := s.GetNetworkStats()
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, stats)
+	}
+}
+
+// GetNodeInfo returns the uptime and peer connections of the node, so that
+// operators can check its connectivity before relying on fee estimates and
+// broadcasts.
+func GetNodeInfo(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		info, err := s.GetNodeInfo()
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, info)
+	}
+}
+
+// GetVersion returns the versions of SatStack and of the connected node, for
+// debugging deployments. Since they only change on restart, clients can
+// revalidate the response using its ETag.
+func GetVersion(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		info := s.GetVersion()
+
+		noCache(ctx)
+		etag := fmt.Sprintf("%s-%s-%d-%s", info.Version, info.GitCommit, info.NodeVersion, info.BuildTime)
+		if notModified(ctx, etag, time.Time{}) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, info)
+	}
+}
+
+// GetSoftForks returns the status of the soft fork deployments of the chain,
+// so that clients can check that a feature like Taproot is active before
+// relying on it.
+func GetSoftForks(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		deployments, err := s.GetSoftForks()
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, deployments)
+	}
+}
+
 func GetStatus(s svc.ExplorerService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, s.GetStatus())
 	}
 }
 
+func GetSupply(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		supply, err := s.GetSupply()
+		if err != nil {
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, supply)
+	}
+}
+
+// GetSubsidy is a gin handler (factory) to get the block subsidy and halving
+// information at the height given by the optional height query parameter.
+// It defaults to the next block to be mined.
+func GetSubsidy(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var height *int64
+		if heightQuery := ctx.Query("height"); heightQuery != "" {
+			n, err := strconv.ParseInt(heightQuery, 10, 64)
+			if err != nil {
+				ctx.String(http.StatusBadRequest, "text/plain",
+					[]byte(fmt.Sprintf("invalid height '%s'", heightQuery)))
+				return
+			}
+
+			height = &n
+		}
+
+		subsidy, err := s.GetSubsidy(height)
+		if errors.Is(err, bus.ErrInvalidHeight) {
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, subsidy)
+	}
+}
+
 func GetTimestamp() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{
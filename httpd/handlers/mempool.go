@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ledgerhq/satstack/bus"
+	"github.com/ledgerhq/satstack/httpd/svc"
+)
+
+// GetMempoolInfo is a gin handler (factory) to get the mempool size and
+// minimum fee rates, useful to show mempool congestion.
+func GetMempoolInfo(s svc.MempoolService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		info, err := s.GetMempoolInfo()
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, info)
+	}
+}
+
+// GetMempoolEntry is a gin handler (factory) to get the mempool data of an
+// unconfirmed transaction, by hash parameter.
+//
+// A 404 status is returned if the transaction is not in the mempool.
+func GetMempoolEntry(s svc.MempoolService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		entry, err := s.GetMempoolEntry(ctx.Param("hash"))
+		if errors.Is(err, bus.ErrNotInMempool) {
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		if err != nil {
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, entry)
+	}
+}
+
+// TestPackageAccept is a gin handler (factory) to test whether a package of
+// raw transactions, for ex a CPFP parent and child, would be accepted in the
+// mempool together. Nothing is broadcast.
+//
+// The body is in the form {"txs": ["<hex>", ...]}, with children after their
+// parents.
+func TestPackageAccept(s svc.MempoolService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Transactions []string `json:"txs" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		result, err := s.TestPackageAccept(request.Transactions)
+		switch {
+		case errors.Is(err, bus.ErrInvalidPackage):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			noCache(ctx)
+			ctx.JSON(http.StatusOK, result)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ledgerhq/satstack/httpd/svc"
+)
+
+// prometheusContentType is the content type of the Prometheus text-based
+// exposition format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// GetMetrics is a gin handler (factory) to expose operational metrics in the
+// Prometheus text-based exposition format, for scraping.
+//
+// Metrics are omitted if they cannot be computed, for ex if the node is
+// unreachable.
+func GetMetrics(s svc.ExplorerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		status := s.GetStatus()
+
+		var metrics strings.Builder
+
+		if status.TipAgeSeconds != nil {
+			writeGauge(&metrics, "satstack_tip_age_seconds",
+				"Seconds elapsed since the block time of the chain tip.",
+				float64(*status.TipAgeSeconds))
+		}
+
+		noCache(ctx)
+		ctx.Data(http.StatusOK, prometheusContentType, []byte(metrics.String()))
+	}
+}
+
+// writeGauge writes a gauge metric with a single sample.
+func writeGauge(w *strings.Builder, name string, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	fmt.Fprintf(w, "%s %g\n", name, value)
+}
@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/btcsuite/btcd/btcjson"
 	"github.com/gin-gonic/gin"
+	"github.com/ledgerhq/satstack/bus"
 	"github.com/ledgerhq/satstack/httpd/svc"
 	log "github.com/sirupsen/logrus"
 )
@@ -20,6 +28,16 @@ func GetTransactionHex(s svc.TransactionsService) gin.HandlerFunc {
 			return
 		}
 
+		// The transaction ID does not commit to the witness data, which
+		// can be malleated while the transaction is unconfirmed. The hex is
+		// therefore tagged by its own digest, and revalidated.
+		noCache(ctx)
+
+		digest := sha256.Sum256([]byte(txHex))
+		if notModified(ctx, hex.EncodeToString(digest[:]), time.Time{}) {
+			return
+		}
+
 		response := gin.H{
 			"transaction_hash": txHash,
 			"hex":              txHex,
@@ -29,6 +47,135 @@ func GetTransactionHex(s svc.TransactionsService) gin.HandlerFunc {
 	}
 }
 
+// GetRawTransaction is a gin handler (factory) to get the hex encoded raw
+// transaction by hash parameter, whether or not it belongs to the wallet.
+func GetRawTransaction(s svc.TransactionsService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		txHash := ctx.Param("hash")
+
+		txHex, err := s.GetRawTransaction(txHash)
+		var rpcErr *btcjson.RPCError
+		switch {
+		case errors.Is(err, bus.ErrTxIndexRequired),
+			errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCNoTxInfo:
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		// Like GetTransactionHex, the witness data may be malleated while
+		// the transaction is unconfirmed.
+		noCache(ctx)
+
+		digest := sha256.Sum256([]byte(txHex))
+		if notModified(ctx, hex.EncodeToString(digest[:]), time.Time{}) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"transaction_hash": txHash,
+			"hex":              txHex,
+		})
+	}
+}
+
+// IsOutputSpent is a gin handler (factory) to check whether an output of a
+// transaction, by hash and index parameters, is spent.
+//
+// Spends by mempool transactions count, unless the include_mempool query
+// parameter is false.
+func IsOutputSpent(s svc.TransactionsService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		vout, err := strconv.ParseUint(ctx.Param("vout"), 10, 32)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain",
+				[]byte(fmt.Sprintf("invalid output index '%s'", ctx.Param("vout"))))
+			return
+		}
+
+		includeMempool, err := strconv.ParseBool(ctx.DefaultQuery("include_mempool", "true"))
+		if err != nil {
+			ctx.String(http.StatusBadRequest, "text/plain",
+				[]byte(fmt.Sprintf("invalid include_mempool '%s'", ctx.Query("include_mempool"))))
+			return
+		}
+
+		spent, err := s.IsOutputSpent(ctx.Param("hash"), uint32(vout), includeMempool)
+		switch {
+		case errors.Is(err, bus.ErrMalformedChainHash):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		case err != nil:
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, gin.H{
+			"spent": spent,
+		})
+	}
+}
+
+// BumpFeeEstimate is a gin handler (factory) to compute the fee required to
+// replace an unconfirmed transaction, by hash parameter, at the fee rate
+// given by the fee_rate query parameter (in sat/vB).
+func BumpFeeEstimate(s svc.TransactionsService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		txHash := ctx.Param("hash")
+
+		feeRate, err := strconv.ParseFloat(ctx.Query("fee_rate"), 64)
+		if err != nil || feeRate <= 0 {
+			ctx.String(http.StatusBadRequest, "text/plain",
+				[]byte(fmt.Sprintf("invalid fee_rate '%s'", ctx.Query("fee_rate"))))
+			return
+		}
+
+		result, err := s.BumpFeeEstimate(txHash, feeRate)
+		switch {
+		case errors.Is(err, bus.ErrNotInMempool):
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrNotReplaceable), errors.Is(err, bus.ErrTransactionConfirmed):
+			ctx.String(http.StatusConflict, "text/plain", []byte(err.Error()))
+		case err != nil:
+			ctx.String(http.StatusInternalServerError, "text/plain", []byte(err.Error()))
+		default:
+			ctx.JSON(http.StatusOK, result)
+		}
+	}
+}
+
+// GetTxMerkleProof is a gin handler (factory) to get a proof of inclusion of
+// a confirmed transaction, by hash parameter.
+//
+// Unconfirmed transactions have no proof, and are reported with a 409
+// Conflict status.
+func GetTxMerkleProof(s svc.TransactionsService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		proof, err := s.GetTxMerkleProof(ctx.Param("hash"))
+		switch {
+		case errors.Is(err, bus.ErrTxUnconfirmed):
+			ctx.String(http.StatusConflict, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrBlockPruned):
+			ctx.String(http.StatusGone, "text/plain", []byte(err.Error()))
+		case err != nil:
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+		default:
+			// A reorg may move the transaction to another block, so the
+			// proof is revalidated against the block it was included in.
+			noCache(ctx)
+
+			if notModified(ctx, proof.BlockHash+"-"+proof.TxID, time.Time{}) {
+				return
+			}
+
+			ctx.JSON(http.StatusOK, proof)
+		}
+	}
+}
+
 func SendTransaction(s svc.TransactionsService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var request struct {
@@ -41,7 +188,17 @@ func SendTransaction(s svc.TransactionsService) gin.HandlerFunc {
 			return
 		}
 
-		txHash, err := s.SendTransaction(request.Transaction)
+		txHash, err := s.SendTransaction(ctx.Request.Context(), request.Transaction)
+
+		var chainErr *bus.MempoolChainError
+		if errors.As(err, &chainErr) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   chainErr.Error(),
+				"details": chainErr,
+			})
+			return
+		}
+
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, err)
 			return
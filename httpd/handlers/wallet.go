@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/gin-gonic/gin"
+	"github.com/ledgerhq/satstack/bus"
+	"github.com/ledgerhq/satstack/httpd/svc"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetWalletTransactions is a gin handler (factory) to incrementally sync the
+// wallet transactions.
+//
+// The optional since query parameter is the lastblock returned by the
+// previous call. If omitted, all wallet transactions are returned. After a
+// reorg, the IDs of transactions that are no longer in the chain are listed
+// in the removed field of the response.
+func GetWalletTransactions(s svc.WalletService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		result, err := s.GetWalletTransactionsSince(ctx.Query("since"))
+		if err != nil {
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		noCache(ctx)
+		ctx.JSON(http.StatusOK, result)
+	}
+}
+
+// GetAccountSummary is a gin handler (factory) to get the totals received and
+// sent by an account, along with its balance, for accounting purposes.
+//
+// The account is identified by its external and internal descriptors, which
+// must be imported in the wallet.
+func GetAccountSummary(s svc.WalletService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Descriptors []string `json:"descriptors" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			log.Error("Failed to bind JSON request")
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		summary, err := s.GetAccountSummary(request.Descriptors)
+		switch {
+		case errors.Is(err, bus.ErrDescriptorNotImported):
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrInvalidDescriptor):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			log.WithField("error", err).Error("Failed to get account summary")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			noCache(ctx)
+			ctx.JSON(http.StatusOK, summary)
+		}
+	}
+}
+
+// GetTransactionsInRange is a gin handler (factory) to get the transactions
+// of an account confirmed between two block heights (inclusive), for ranged
+// and incremental resyncs.
+func GetTransactionsInRange(s svc.WalletService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Descriptors []string `json:"descriptors" binding:"required"`
+			StartHeight *int64   `json:"start_height" binding:"required"`
+			EndHeight   *int64   `json:"end_height" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			log.Error("Failed to bind JSON request")
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		txs, err := s.GetTransactionsInRange(request.Descriptors, *request.StartHeight, *request.EndHeight)
+		switch {
+		case errors.Is(err, bus.ErrDescriptorNotImported):
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrInvalidDescriptor), errors.Is(err, bus.ErrInvalidHeight):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			log.WithField("error", err).Error("Failed to get transactions in range")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			noCache(ctx)
+			ctx.JSON(http.StatusOK, txs)
+		}
+	}
+}
+
+// GetNextUnusedAddress is a gin handler (factory) to get a fresh receive
+// address of an account, ie the first address of its external descriptor
+// that never received funds.
+func GetNextUnusedAddress(s svc.WalletService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Descriptor string `json:"descriptor" binding:"required"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			log.Error("Failed to bind JSON request")
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		address, err := s.GetNextUnusedAddress(request.Descriptor)
+		switch {
+		case errors.Is(err, bus.ErrDescriptorNotImported):
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrNoUnusedAddress):
+			ctx.String(http.StatusConflict, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrInvalidDescriptor), errors.Is(err, bus.ErrDescriptorNotRanged):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			log.WithField("error", err).Error("Failed to get next unused address")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			noCache(ctx)
+			ctx.JSON(http.StatusOK, address)
+		}
+	}
+}
+
+// SelectCoins is a gin handler (factory) to preview the UTXOs of an account
+// that would be spent to pay a target amount (in satoshis) at a fee rate (in
+// sat/vB), along with the resulting fee and change. Nothing is signed.
+//
+// UTXOs with less than min_conf confirmations are not selected. It defaults
+// to 1, and can be set to 0 to include unconfirmed UTXOs.
+func SelectCoins(s svc.WalletService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request struct {
+			Descriptors []string       `json:"descriptors" binding:"required"`
+			Target      btcutil.Amount `json:"target" binding:"required"`
+			FeeRate     float64        `json:"fee_rate" binding:"required"`
+			MinConf     *int           `json:"min_conf"`
+		}
+
+		if err := ctx.BindJSON(&request); err != nil {
+			log.Error("Failed to bind JSON request")
+			ctx.JSON(http.StatusBadRequest, err)
+			return
+		}
+
+		minConf := 1
+		if request.MinConf != nil {
+			minConf = *request.MinConf
+		}
+
+		if minConf < 0 {
+			ctx.String(http.StatusBadRequest, "text/plain",
+				[]byte(fmt.Sprintf("invalid min_conf %d", minConf)))
+			return
+		}
+
+		selection, err := s.SelectCoins(request.Descriptors, request.Target, request.FeeRate, minConf)
+		switch {
+		case errors.Is(err, bus.ErrDescriptorNotImported):
+			ctx.String(http.StatusNotFound, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrInsufficientFunds):
+			ctx.String(http.StatusConflict, "text/plain", []byte(err.Error()))
+		case errors.Is(err, bus.ErrInvalidDescriptor), errors.Is(err, bus.ErrInvalidCoinSelection):
+			ctx.String(http.StatusBadRequest, "text/plain", []byte(err.Error()))
+		case err != nil:
+			log.WithField("error", err).Error("Failed to select coins")
+			ctx.String(http.StatusServiceUnavailable, "text/plain", []byte(err.Error()))
+		default:
+			noCache(ctx)
+			ctx.JSON(http.StatusOK, selection)
+		}
+	}
+}
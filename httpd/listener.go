@@ -0,0 +1,71 @@
+package httpd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteSet selects the endpoints served by a listener.
+type RouteSet string
+
+const (
+	// AllRoutes serves both the explorer and the control endpoints.
+	AllRoutes RouteSet = "all"
+
+	// ExplorerRoutes serves the read-only explorer endpoints, the metrics
+	// and the version.
+	ExplorerRoutes RouteSet = "explorer"
+
+	// ControlRoutes serves the privileged control endpoints.
+	ControlRoutes RouteSet = "control"
+)
+
+// Serves reports whether the route set includes the given one. The zero
+// value serves all the routes.
+func (r RouteSet) Serves(routes RouteSet) bool {
+	return r == "" || r == AllRoutes || r == routes
+}
+
+// listenerAuthSuffix requires the API token for the explorer endpoints of a
+// listener.
+const listenerAuthSuffix = "+auth"
+
+// Listener is an address to listen on, along with the endpoints served
+// there.
+type Listener struct {
+	Address string
+	Routes  RouteSet
+
+	// RequireToken requires the API token for the explorer endpoints
+	// served by the listener. The control endpoints always require it.
+	RequireToken bool
+}
+
+// ParseListener parses a listener spec in the ADDRESS[=ROUTES[+auth]]
+// format, for ex: "127.0.0.1:20000=control" or "[::]:20001=explorer+auth".
+// Without ROUTES, the listener serves all the routes.
+func ParseListener(spec string) (Listener, error) {
+	address, routes, found := strings.Cut(strings.TrimSpace(spec), "=")
+	if address == "" {
+		return Listener{}, fmt.Errorf("invalid listener %q: missing address", spec)
+	}
+
+	if !found {
+		return Listener{Address: address, Routes: AllRoutes}, nil
+	}
+
+	routes, requireToken := strings.CutSuffix(routes, listenerAuthSuffix)
+
+	switch RouteSet(routes) {
+	case AllRoutes, ExplorerRoutes, ControlRoutes:
+	default:
+		return Listener{}, fmt.Errorf("invalid listener %q: routes must be one of %s, %s or %s",
+			spec, AllRoutes, ExplorerRoutes, ControlRoutes)
+	}
+
+	return Listener{
+		Address:      address,
+		Routes:       RouteSet(routes),
+		RequireToken: requireToken,
+	}, nil
+}
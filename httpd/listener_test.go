@@ -0,0 +1,79 @@
+package httpd
+
+import "testing"
+
+func TestParseListener(t *testing.T) {
+	tests := []struct {
+		spec  string
+		want  Listener
+		valid bool
+	}{
+		{spec: "127.0.0.1:20000", want: Listener{Address: "127.0.0.1:20000", Routes: AllRoutes}, valid: true},
+		{spec: ":20000", want: Listener{Address: ":20000", Routes: AllRoutes}, valid: true},
+		{spec: " 127.0.0.1:20000=control ", want: Listener{Address: "127.0.0.1:20000", Routes: ControlRoutes}, valid: true},
+		{spec: "127.0.0.1:20000=all", want: Listener{Address: "127.0.0.1:20000", Routes: AllRoutes}, valid: true},
+		{
+			spec:  "192.168.1.10:20001=explorer+auth",
+			want:  Listener{Address: "192.168.1.10:20001", Routes: ExplorerRoutes, RequireToken: true},
+			valid: true,
+		},
+		{spec: "[::1]:20000", want: Listener{Address: "[::1]:20000", Routes: AllRoutes}, valid: true},
+		{
+			spec:  "[::]:20001=explorer+auth",
+			want:  Listener{Address: "[::]:20001", Routes: ExplorerRoutes, RequireToken: true},
+			valid: true,
+		},
+		{spec: ""},
+		{spec: "=control"},
+		{spec: "127.0.0.1:20000="},
+		{spec: "127.0.0.1:20000=+auth"},
+		{spec: "127.0.0.1:20000=admin"},
+		{spec: "127.0.0.1:20000=Control"},
+		{spec: "127.0.0.1:20000=control+AUTH"},
+		{spec: "127.0.0.1:20000=control+auth+auth"},
+		{spec: "[::]:20001=explorer=control"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseListener(tt.spec)
+
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("ParseListener(%q) = %+v, want an error", tt.spec, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseListener(%q) error = %v", tt.spec, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("ParseListener(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestRouteSetServes(t *testing.T) {
+	tests := []struct {
+		routes   RouteSet
+		explorer bool
+		control  bool
+	}{
+		{routes: "", explorer: true, control: true},
+		{routes: AllRoutes, explorer: true, control: true},
+		{routes: ExplorerRoutes, explorer: true},
+		{routes: ControlRoutes, control: true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.routes.Serves(ExplorerRoutes); got != tt.explorer {
+			t.Errorf("RouteSet(%q).Serves(%s) = %v, want %v", tt.routes, ExplorerRoutes, got, tt.explorer)
+		}
+
+		if got := tt.routes.Serves(ControlRoutes); got != tt.control {
+			t.Errorf("RouteSet(%q).Serves(%s) = %v, want %v", tt.routes, ControlRoutes, got, tt.control)
+		}
+	}
+}
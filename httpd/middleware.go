@@ -0,0 +1,293 @@
+package httpd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ledgerhq/satstack/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// requestIDHeader is the HTTP header used to propagate request IDs.
+const requestIDHeader = "X-Request-ID"
+
+// requestID is a gin middleware that assigns a correlation ID to each request.
+//
+// The ID is taken from the X-Request-ID header if provided by the client, or
+// generated otherwise. It is echoed back in the response headers, and stored
+// in the request context so that logs emitted down to the bus can be
+// correlated.
+func requestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = utils.NewRequestID()
+		}
+
+		ctx.Header(requestIDHeader, id)
+		ctx.Request = ctx.Request.WithContext(
+			utils.WithRequestID(ctx.Request.Context(), id))
+
+		ctx.Next()
+	}
+}
+
+// logger is a gin middleware that logs each request through logrus, instead
+// of the default gin logger that writes plain text to stdout.
+//
+// This ensures that httpd logs honour the configured log format, and can be
+// filtered by the "component" field.
+func logger() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		path := ctx.Request.URL.Path
+
+		ctx.Next()
+
+		fields := log.WithContext(ctx.Request.Context()).WithFields(log.Fields{
+			"component": "httpd",
+			"status":    ctx.Writer.Status(),
+			"method":    ctx.Request.Method,
+			"path":      path,
+			"latency":   time.Since(start).String(),
+			"clientIP":  ctx.ClientIP(),
+		})
+
+		if len(ctx.Errors) > 0 {
+			fields.WithField("error", ctx.Errors.String()).Error("Request failed")
+			return
+		}
+
+		fields.Info("Request handled")
+	}
+}
+
+// corsMaxAge is how long browsers may cache the result of a preflight request.
+const corsMaxAge = 10 * time.Minute
+
+// cors is a gin middleware that lets browser-based clients from the allowed
+// origins call SatStack directly. An origin of "*" allows any origin. No
+// allowed origins disables the middleware, so that browsers enforce the
+// same-origin policy.
+//
+// Preflight requests from allowed origins are answered with a 204 No Content
+// status, and those from other origins with a 403 Forbidden status.
+func cors(origins []string, methods []string, headers []string) gin.HandlerFunc {
+	if len(origins) == 0 {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	allowedMethods := strings.Join(methods, ", ")
+	allowedHeaders := strings.Join(headers, ", ")
+	maxAge := strconv.Itoa(int(corsMaxAge.Seconds()))
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		preflight := ctx.Request.Method == http.MethodOptions &&
+			ctx.GetHeader("Access-Control-Request-Method") != ""
+
+		ctx.Writer.Header().Add("Vary", "Origin")
+
+		switch {
+		case allowed["*"]:
+			ctx.Header("Access-Control-Allow-Origin", "*")
+		case allowed[origin]:
+			ctx.Header("Access-Control-Allow-Origin", origin)
+		case preflight:
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		default:
+			// Let the browser block the response.
+			ctx.Next()
+			return
+		}
+
+		if !preflight {
+			ctx.Header("Access-Control-Expose-Headers", requestIDHeader)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("Access-Control-Allow-Methods", allowedMethods)
+		ctx.Header("Access-Control-Allow-Headers", allowedHeaders)
+		ctx.Header("Access-Control-Max-Age", maxAge)
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// apiKeyHeader is an alternative to the Authorization header, to pass the
+// API token without the "Bearer" scheme.
+const apiKeyHeader = "X-API-Key"
+
+// tokenAuth is a gin middleware that rejects requests without the given API
+// token with a 401 Unauthorized status. The token is passed either as a
+// bearer token in the Authorization header, or in the X-API-Key header. An
+// empty token disables the middleware.
+func tokenAuth(token string) gin.HandlerFunc {
+	if token == "" {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	// Compare digests, so that the comparison does not leak the length of
+	// the token either.
+	expected := sha256.Sum256([]byte(token))
+
+	return func(ctx *gin.Context) {
+		provided := ctx.GetHeader(apiKeyHeader)
+		if bearer, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer "); ok {
+			provided = bearer
+		}
+
+		digest := sha256.Sum256([]byte(provided))
+		if provided == "" || subtle.ConstantTimeCompare(digest[:], expected[:]) != 1 {
+			log.WithContext(ctx.Request.Context()).WithFields(log.Fields{
+				"component": "httpd",
+				"path":      ctx.Request.URL.Path,
+				"clientIP":  ctx.ClientIP(),
+			}).Warn("Rejected request with missing or invalid API token")
+
+			ctx.Header("WWW-Authenticate", "Bearer")
+			ctx.String(http.StatusUnauthorized, "text/plain", []byte("missing or invalid API token"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// NewConcurrencyLimiter returns a gin middleware that caps the number of
+// requests processed concurrently, to protect the node from bursts of
+// RPC-heavy requests. The limit applies to all the routers using the same
+// middleware.
+//
+// Requests exceeding the limit wait for up to queueTimeout for a slot to be
+// freed, and are otherwise rejected with a 429 Too Many Requests status. A
+// limit of zero disables the middleware.
+func NewConcurrencyLimiter(limit int, queueTimeout time.Duration) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+
+	return func(ctx *gin.Context) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if !waitForSlot(ctx, slots, queueTimeout) {
+				ctx.Header("Retry-After", "1")
+				ctx.String(http.StatusTooManyRequests, "text/plain",
+					[]byte("too many concurrent requests"))
+				ctx.Abort()
+				return
+			}
+		}
+
+		defer func() { <-slots }()
+
+		ctx.Next()
+	}
+}
+
+// waitForSlot waits for up to timeout for a slot to be freed, and reports
+// whether it was acquired. It gives up early if the client goes away.
+func waitForSlot(ctx *gin.Context, slots chan struct{}, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Request.Context().Done():
+		return false
+	}
+}
+
+// gzipMinSize is the size in bytes below which responses are not compressed,
+// since the gzip overhead outweighs the savings.
+const gzipMinSize = 1024
+
+// gzipWriter buffers the response body, so that the compression decision can
+// be made once its size is known.
+type gzipWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// compress is a gin middleware that compresses responses with gzip, for
+// clients sending an Accept-Encoding: gzip header.
+//
+// Responses smaller than gzipMinSize are sent as is.
+func compress() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !strings.Contains(ctx.GetHeader("Accept-Encoding"), "gzip") {
+			ctx.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		ctx.Writer = writer.ResponseWriter
+		ctx.Header("Vary", "Accept-Encoding")
+
+		body := writer.body.Bytes()
+		if len(body) < gzipMinSize || ctx.Writer.Header().Get("Content-Encoding") != "" {
+			if len(body) > 0 {
+				_, _ = ctx.Writer.Write(body)
+			}
+			return
+		}
+
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		if _, err := gz.Write(body); err != nil || gz.Close() != nil {
+			_, _ = ctx.Writer.Write(body)
+			return
+		}
+
+		ctx.Header("Content-Encoding", "gzip")
+		ctx.Writer.Header().Del("Content-Length")
+		_, _ = ctx.Writer.Write(compressed.Bytes())
+	}
+}
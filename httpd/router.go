@@ -6,48 +6,167 @@ import (
 	"github.com/ledgerhq/satstack/httpd/svc"
 )
 
-func GetRouter(s *svc.Service) *gin.Engine {
-	engine := gin.Default()
+// Options customizes the behaviour of the router returned by GetRouter.
+type Options struct {
+	// Limiter caps the number of explorer requests processed concurrently,
+	// see NewConcurrencyLimiter. Share it between the routers of all the
+	// listeners, so that the limit applies to them as a whole. Nil means no
+	// limit.
+	Limiter gin.HandlerFunc
+
+	// Gzip enables compressing large responses, for clients that accept it.
+	Gzip bool
+
+	// APIToken is required to call the control endpoints, if set.
+	APIToken string
+
+	// ProtectExplorer also requires the APIToken for the explorer endpoints
+	// and the metrics.
+	ProtectExplorer bool
+
+	// CORSAllowedOrigins lists the origins of browser-based clients allowed
+	// to call SatStack. Empty means same-origin only.
+	CORSAllowedOrigins []string
+
+	// CORSAllowedMethods lists the methods allowed in cross-origin requests.
+	CORSAllowedMethods []string
+
+	// CORSAllowedHeaders lists the headers allowed in cross-origin requests.
+	CORSAllowedHeaders []string
+
+	// Routes selects the endpoints served by the router. The zero value
+	// serves all of them.
+	Routes RouteSet
+}
+
+func GetRouter(s *svc.Service, opts Options) *gin.Engine {
+	engine := gin.New()
+	engine.Use(requestID(), logger(), gin.Recovery(),
+		cors(opts.CORSAllowedOrigins, opts.CORSAllowedMethods, opts.CORSAllowedHeaders))
+
+	if opts.Gzip {
+		engine.Use(compress())
+	}
 
 	engine.GET("timestamp", handlers.GetTimestamp())
 
-	// controlRouter exposes endpoints that can be used to programmatically
-	// control SatStack (for ex, from Ledger Live).
-	controlRouter := engine.Group("control")
+	if opts.Routes.Serves(ControlRoutes) {
+		registerControlRoutes(engine, s, opts)
+	}
+
+	if opts.Routes.Serves(ExplorerRoutes) {
+		registerExplorerRoutes(engine, s, opts)
+	}
+
+	return engine
+}
+
+// registerControlRoutes registers the endpoints that can be used to
+// programmatically control SatStack (for ex, from Ledger Live).
+func registerControlRoutes(engine *gin.Engine, s *svc.Service, opts Options) {
+	controlRouter := engine.Group("control", tokenAuth(opts.APIToken))
 	{
 		controlRouter.GET("descriptors/import", handlers.ImportAccounts(s))
 		controlRouter.POST("descriptors/has", handlers.HasDescriptor(s))
+		controlRouter.POST("descriptors/info", handlers.GetDescriptorInfo(s))
+		controlRouter.POST("descriptors/derive", handlers.DeriveAddressAt(s))
+		controlRouter.GET("descriptors/export", handlers.ExportDescriptors(s))
+		controlRouter.POST("descriptors/scan", handlers.ScanTxOutSet(s))
+		controlRouter.POST("abort-rescan", handlers.AbortRescan(s))
+		controlRouter.POST("reimport", handlers.ReimportAccount(s))
+		controlRouter.GET("rescan-checkpoint", handlers.GetRescanCheckpoint(s))
+		controlRouter.GET("rescan-needed", handlers.RescanNeeded(s))
+		controlRouter.GET("peers", handlers.GetPeers(s))
+		controlRouter.GET("chaintips", handlers.GetChainTips(s))
+		controlRouter.POST("rpc", handlers.CallRPC(s))
+		controlRouter.POST("loglevel", handlers.SetLogLevel(s))
+	}
+}
+
+// registerExplorerRoutes registers the read-only explorer endpoints, the
+// metrics and the version.
+func registerExplorerRoutes(engine *gin.Engine, s *svc.Service, opts Options) {
+	explorerAuth := tokenAuth("")
+	if opts.ProtectExplorer {
+		explorerAuth = tokenAuth(opts.APIToken)
 	}
 
+	engine.GET("metrics", explorerAuth, handlers.GetMetrics(s))
+	engine.GET("version", explorerAuth, handlers.GetVersion(s))
+
 	// We support both Ledger Blockchain Explorer v2 and v3. The version here
 	// is irrelevant.
-	baseRouter := engine.Group("blockchain/:version")
+	baseRouter := engine.Group("blockchain/:version", explorerAuth)
 	{
 		baseRouter.GET("explorer/_health", handlers.GetHealth(s))
 		baseRouter.GET("explorer/status", handlers.GetStatus(s))
 		baseRouter.GET("btc/network", handlers.GetNetwork(s))
+		baseRouter.GET("btc/node", handlers.GetNodeInfo(s))
+		baseRouter.GET("btc/softforks", handlers.GetSoftForks(s))
+	}
+
+	// Explorer endpoints are RPC-heavy, so they are subject to the
+	// concurrency limit.
+	var limiter []gin.HandlerFunc
+	if opts.Limiter != nil {
+		limiter = append(limiter, opts.Limiter)
 	}
 
-	currencyRouter := baseRouter.Group(s.Bus.Currency)
+	currencyRouter := baseRouter.Group(s.Bus.Currency, limiter...)
 	{
 		currencyRouter.GET("fees", handlers.GetFees(s))
+		currencyRouter.GET("fees/priority", handlers.GetPriorityFee(s))
+		currencyRouter.GET("fees/dust", handlers.GetDustThreshold(s))
+		currencyRouter.GET("supply", handlers.GetSupply(s))
+		currencyRouter.GET("subsidy", handlers.GetSubsidy(s))
+		currencyRouter.GET("network-stats", handlers.GetNetworkStats(s))
+	}
+
+	mempoolRouter := currencyRouter.Group("/mempool")
+	{
+		mempoolRouter.GET("", handlers.GetMempoolInfo(s))
+		mempoolRouter.GET(":hash", handlers.GetMempoolEntry(s))
+		mempoolRouter.POST("testaccept", handlers.TestPackageAccept(s))
 	}
 
 	blocksRouter := currencyRouter.Group("/blocks")
 	{
+		blocksRouter.GET("latest", handlers.GetLatestBlock(s))
 		blocksRouter.GET(":block", handlers.GetBlock(s))
+		blocksRouter.GET(":block/hex", handlers.GetRawBlock(s))
+		blocksRouter.GET(":block/stats", handlers.GetBlockStats(s))
+		blocksRouter.GET(":block/filter", handlers.GetBlockFilter(s))
 	}
 
 	transactionsRouter := currencyRouter.Group("/transactions")
 	{
 		transactionsRouter.GET(":hash/hex", handlers.GetTransactionHex(s))
+		transactionsRouter.GET(":hash/raw", handlers.GetRawTransaction(s))
+		transactionsRouter.GET(":hash/outputs/:vout/spent", handlers.IsOutputSpent(s))
+		transactionsRouter.GET(":hash/bumpfee", handlers.BumpFeeEstimate(s))
+		transactionsRouter.GET(":hash/proof", handlers.GetTxMerkleProof(s))
 		transactionsRouter.POST("send", handlers.SendTransaction(s))
 	}
 
 	addressesRouter := currencyRouter.Group("/addresses")
 	{
 		addressesRouter.GET(":addresses/transactions", handlers.GetAddresses(s))
+		addressesRouter.GET(":addresses/utxos", handlers.GetUTXOs(s))
+		addressesRouter.GET(":addresses/balance", handlers.GetBalance(s))
+		addressesRouter.POST("balances", handlers.GetBalances(s))
 	}
 
-	return engine
+	xpubsRouter := currencyRouter.Group("/xpubs")
+	{
+		xpubsRouter.GET(":xpub/balance", handlers.GetXPubBalance(s))
+	}
+
+	walletRouter := currencyRouter.Group("/wallet")
+	{
+		walletRouter.GET("transactions", handlers.GetWalletTransactions(s))
+		walletRouter.POST("transactions/range", handlers.GetTransactionsInRange(s))
+		walletRouter.POST("summary", handlers.GetAccountSummary(s))
+		walletRouter.POST("receive-address", handlers.GetNextUnusedAddress(s))
+		walletRouter.POST("coinselection", handlers.SelectCoins(s))
+	}
 }
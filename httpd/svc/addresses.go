@@ -1,6 +1,9 @@
 package svc
 
 import (
+	"context"
+	"sort"
+
 	"github.com/ledgerhq/satstack/types"
 	"github.com/ledgerhq/satstack/utils"
 
@@ -9,7 +12,13 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func (s *Service) GetAddresses(addresses []string, blockHash *string, blockHeight *int32) (types.Addresses, error) {
+// GetAddresses is a service method to get the transactions of the given
+// addresses.
+//
+// If page is not nil, only a page of transactions is returned, ordered by
+// confirmation height descending (unconfirmed first), then by transaction ID.
+// The cursor of the next page is returned if more transactions exist.
+func (s *Service) GetAddresses(ctx context.Context, addresses []string, blockHash *string, blockHeight *int32, page *types.HistoryPage) (types.Addresses, error) {
 	// Cache the results of GetTransaction calls against the TxID. The avoids
 	// wasteful querying of the Bitcoin node for the same TxID, within the
 	// lifecycle of this function invocation.
@@ -29,27 +38,46 @@ func (s *Service) GetAddresses(addresses []string, blockHash *string, blockHeigh
 
 	txResults, err := s.Bus.ListTransactions(blockHash)
 	if err != nil {
-		log.WithFields(log.Fields{
+		log.WithContext(ctx).WithFields(log.Fields{
 			"error":     err,
 			"blockHash": nil,
 		}).Error("Unable to fetch transaction")
 		return types.Addresses{}, err
 	}
 
-	walletTxs := s.filterTransactionsByAddresses(addresses, txResults, blockchainInfo.Headers)
+	// Snapshot the tip once, so that all the transactions share the same
+	// confirmations baseline.
+	tipHeight := blockchainInfo.Blocks
 
-	txs := make([]types.Transaction, 0, len(walletTxs))
-	for _, txn := range walletTxs {
-		if blockHeight != nil {
+	walletTxs := s.filterTransactionsByAddresses(ctx, addresses, txResults, tipHeight)
+
+	// Filter by block height before paginating, so that pages are not
+	// shortened by the transactions filtered out.
+	if blockHeight != nil {
+		filtered := walletTxs[:0]
+		for _, txn := range walletTxs {
 			if !(txn.BlockHeight != nil || *txn.BlockHeight == *blockHeight) {
 				continue
 			}
+
+			filtered = append(filtered, txn)
 		}
 
+		walletTxs = filtered
+	}
+
+	// Paginate before fetching the transactions, which is the costly part.
+	var next *string
+	if page != nil {
+		walletTxs, next = paginateTransactions(walletTxs, page)
+	}
+
+	txs := make([]types.Transaction, 0, len(walletTxs))
+	for _, txn := range walletTxs {
 		block := blockFromTxResult(txn)
-		tx, err := s.GetTransaction(txn.TxID, block, blockchainInfo.Headers)
+		tx, err := s.GetTransaction(txn.TxID, block, tipHeight)
 		if err != nil {
-			log.WithFields(log.Fields{
+			log.WithContext(ctx).WithFields(log.Fields{
 				"error": err,
 				"hash":  txn.TxID,
 			}).Error("Unable to fetch transaction")
@@ -61,18 +89,82 @@ func (s *Service) GetAddresses(addresses []string, blockHash *string, blockHeigh
 		// Be defensive here with the retrieved transaction, to avoid
 		// nil pointer dereference.
 		if tx != nil {
+			// The wallet reports negative confirmations for transactions
+			// conflicting with the active chain.
+			tx.Confirmations = walletTxConfirmations(txn.Confirmations, block, int64(tipHeight))
+
 			txs = append(txs, *tx)
 		}
 	}
 
 	return types.Addresses{
-		Truncated:    false,
+		Truncated:    next != nil,
 		Transactions: txs,
+		Next:         next,
 	}, nil
 }
 
+// paginateTransactions sorts the wallet transactions in history order, and
+// returns those of the given page, along with the cursor of the next page if
+// more transactions exist.
+func paginateTransactions(
+	txs []btcjson.ListTransactionsResult, page *types.HistoryPage,
+) ([]btcjson.ListTransactionsResult, *string) {
+	sort.Slice(txs, func(i, j int) bool {
+		return historyCursor(txs[i]).Before(historyCursor(txs[j]))
+	})
+
+	start := 0
+	if page.After != nil {
+		start = sort.Search(len(txs), func(i int) bool {
+			return page.After.Before(historyCursor(txs[i]))
+		})
+	}
+
+	end := start + page.Limit
+	if end >= len(txs) {
+		return txs[start:], nil
+	}
+
+	next := historyCursor(txs[end-1]).String()
+	return txs[start:end], &next
+}
+
+func historyCursor(tx btcjson.ListTransactionsResult) types.HistoryCursor {
+	height := int64(types.UnconfirmedHeight)
+	if tx.BlockHeight != nil && tx.Confirmations > 0 {
+		height = int64(*tx.BlockHeight)
+	}
+
+	return types.HistoryCursor{Height: height, TxID: tx.TxID}
+}
+
+// GetUTXOs is a service method to list the UTXOs of the given addresses,
+// with at least minConf confirmations.
+func (s *Service) GetUTXOs(addresses []string, minConf int) ([]types.UnspentOutput, error) {
+	return s.Bus.ListUnspent(addresses, minConf)
+}
+
+// GetBalance is a service method to get the aggregate balance of the given
+// addresses, with at least minConf confirmations.
+func (s *Service) GetBalance(addresses []string, minConf int) (*types.Balance, error) {
+	return s.Bus.GetBalance(addresses, minConf)
+}
+
+// GetBalances is a service method to get the balance of each of the given
+// addresses, with at least minConf confirmations.
+func (s *Service) GetBalances(addresses []string, minConf int) (map[string]types.Balance, error) {
+	return s.Bus.GetBalances(addresses, minConf)
+}
+
+// GetXPubBalance is a service method to get the aggregate balance of the
+// account of an extended public key.
+func (s *Service) GetXPubBalance(xpub string, scheme string, gapLimit int, minConf int) (*types.XPubBalance, error) {
+	return s.Bus.GetXPubBalance(xpub, scheme, gapLimit, minConf)
+}
+
 func (s *Service) filterTransactionsByAddresses(
-	addresses []string, txs []btcjson.ListTransactionsResult, bestBlockHeight int32,
+	ctx context.Context, addresses []string, txs []btcjson.ListTransactionsResult, bestBlockHeight int32,
 ) []btcjson.ListTransactionsResult {
 	var result []btcjson.ListTransactionsResult
 	var visited []string
@@ -82,7 +174,7 @@ func (s *Service) filterTransactionsByAddresses(
 			block := blockFromTxResult(tx)
 			tx2, err := s.GetTransaction(tx.TxID, block, bestBlockHeight)
 			if err != nil {
-				log.WithFields(log.Fields{
+				log.WithContext(ctx).WithFields(log.Fields{
 					"error":    err,
 					"hash":     tx.TxID,
 					"category": tx.Category,
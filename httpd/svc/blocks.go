@@ -26,6 +26,50 @@ func (s *Service) GetBlock(ref string) (*types.Block, error) {
 	return block, nil
 }
 
+// GetRawBlock is a service method to get the hex-encoded serialized Block
+// by a string reference.
+func (s *Service) GetRawBlock(ref string) (string, error) {
+	rawBlockHash, err := s.getBlockHashByReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Bus.GetRawBlock(rawBlockHash)
+}
+
+// GetBlockStats is a service method to get the statistics of a Block by a
+// string reference.
+func (s *Service) GetBlockStats(ref string) (*types.BlockStats, error) {
+	rawBlockHash, err := s.getBlockHashByReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Bus.GetBlockStats(rawBlockHash)
+}
+
+// GetBlockFilter is a service method to get the compact block filter of a
+// Block by a string reference.
+func (s *Service) GetBlockFilter(ref string) (*types.BlockFilter, error) {
+	rawBlockHash, err := s.getBlockHashByReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Bus.GetBlockFilter(rawBlockHash)
+}
+
+// GetLatestBlock is a service method to get a summary of the chain tip
+// Block, including its number of transactions.
+func (s *Service) GetLatestBlock() (*types.Block, error) {
+	hash, err := s.Bus.GetBestBlockHash()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Bus.GetBlockSummary(hash)
+}
+
 func (s *Service) getBlockHashByReference(ref string) (*chainhash.Hash, error) {
 	switch {
 	case ref == "current":
@@ -1,16 +1,19 @@
 package svc
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/btcsuite/btcd/btcjson"
 	"github.com/ledgerhq/satstack/bus"
 	"github.com/ledgerhq/satstack/config"
+	"github.com/ledgerhq/satstack/types"
 	log "github.com/sirupsen/logrus"
 )
 
 func (s *Service) ImportAccounts(accounts []config.Account) {
 	go func() {
-		if err := s.Bus.ImportAccounts(accounts); err != nil {
+		if err := s.Bus.ImportAccounts(accounts, false); err != nil {
 			log.WithFields(log.Fields{
 				"error": err,
 			}).Error("Failed to import accounts")
@@ -18,13 +21,130 @@ func (s *Service) ImportAccounts(accounts []config.Account) {
 	}()
 }
 
+// ReimportAccount reimports the configured account with the given label in
+// the background, and rescans the wallet from its birthday.
+func (s *Service) ReimportAccount(label string) error {
+	var account *config.Account
+	accounts := s.Bus.Accounts()
+	for i := range accounts {
+		if label != "" && accounts[i].Label == label {
+			account = &accounts[i]
+			break
+		}
+	}
+
+	if account == nil {
+		return fmt.Errorf("%w: %s", bus.ErrAccountNotFound, label)
+	}
+
+	return s.Bus.ReimportAccount(*account)
+}
+
+// ReloadAccounts replaces the accounts of the configuration, and imports
+// those that were added. This is a blocking operation, which waits for the
+// scan in progress to complete, if any.
+//
+// The accounts are only replaced once the added ones are imported, so that a
+// failed import is retried on the next reload.
+//
+// Bitcoin Core cannot remove descriptors from a wallet, so the descriptors of
+// removed accounts are only logged, and keep being watched.
+func (s *Service) ReloadAccounts(accounts []config.Account) error {
+	added, removed := config.DiffAccounts(s.Bus.Accounts(), accounts)
+
+	for _, account := range removed {
+		log.WithField(
+			"label", account.Label,
+		).Warn("Account removed from config, its descriptors remain watched by the wallet")
+	}
+
+	if len(added) == 0 {
+		log.Info("No accounts added to config")
+	} else {
+		log.WithField("accounts", len(added)).Info("Importing accounts added to config")
+
+		if err := s.Bus.ImportAddedAccounts(added); err != nil {
+			return err
+		}
+	}
+
+	s.Bus.SetAccounts(accounts)
+
+	return nil
+}
+
+// PreviewImport returns the descriptors that ImportAccounts would import,
+// without importing them.
+func (s *Service) PreviewImport(accounts []config.Account) (*bus.ImportPreview, error) {
+	return s.Bus.PreviewImport(accounts)
+}
+
+// GetDescriptorInfo returns how the node interprets the given descriptor.
+func (s *Service) GetDescriptorInfo(descriptor string) (*types.DescriptorInfo, error) {
+	return s.Bus.GetDescriptorInfo(descriptor)
+}
+
+// DeriveAddressAt returns the address at the given index of a ranged
+// descriptor.
+func (s *Service) DeriveAddressAt(descriptor string, index int) (*types.DerivedAddress, error) {
+	address, err := s.Bus.DeriveAddressAt(descriptor, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DerivedAddress{
+		Descriptor: descriptor,
+		Index:      index,
+		Address:    address,
+	}, nil
+}
+
+// ScanTxOutSet returns the UTXOs matching the given descriptors, without
+// importing them into the wallet.
+func (s *Service) ScanTxOutSet(descriptors []string) (*types.ScanResult, error) {
+	return s.Bus.ScanTxOutSet(descriptors)
+}
+
+// GetPeers returns a page of the peers connected to the node, starting at
+// offset, with at most limit peers.
+func (s *Service) GetPeers(offset int, limit int) (*types.Peers, error) {
+	peers, err := s.Bus.GetPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &types.Peers{Total: len(peers), Peers: []types.PeerInfo{}}
+	if offset >= len(peers) {
+		return page, nil
+	}
+
+	end := offset + limit
+	if end > len(peers) {
+		end = len(peers)
+	}
+
+	page.Peers = peers[offset:end]
+	return page, nil
+}
+
+// ExportDescriptors returns the descriptors watched by the wallet, without
+// private keys.
+func (s *Service) ExportDescriptors() ([]string, error) {
+	return s.Bus.ExportDescriptors()
+}
+
+// GetChainTips returns the tips of the block tree known by the node.
+func (s *Service) GetChainTips() ([]types.ChainTip, error) {
+	return s.Bus.GetChainTips()
+}
+
 func (s *Service) HasDescriptor(descriptor string) (bool, error) {
-	client, err := s.Bus.ClientFactory()
+	client, err := s.Bus.AcquireClient()
 	if err != nil {
 		return false, err
 	}
 
-	defer client.Shutdown()
+	defer s.Bus.ReleaseClient(client)
 
 	canonicalDesc, err := bus.GetCanonicalDescriptor(client, descriptor)
 	if err != nil {
@@ -48,3 +168,103 @@ func (s *Service) HasDescriptor(descriptor string) (bool, error) {
 
 	return true, nil
 }
+
+// AbortRescan stops the wallet rescan in progress, and reports whether there
+// was one to abort.
+func (s *Service) AbortRescan() (bool, error) {
+	return s.Bus.AbortRescan()
+}
+
+// RescanNeeded returns the descriptors of the configured accounts that need a
+// rescan from the birthday of their account.
+func (s *Service) RescanNeeded() ([]string, error) {
+	return s.Bus.RescanNeeded()
+}
+
+// GetRescanCheckpoint returns the last block that the wallet was rescanned up
+// to, along with its time.
+func (s *Service) GetRescanCheckpoint() (*bus.RescanCheckpoint, error) {
+	height, err := s.Bus.GetRescanCheckpoint()
+	if errors.Is(err, bus.ErrNeverRescanned) {
+		return &bus.RescanCheckpoint{Rescanned: false}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	blockTime, err := s.Bus.BlockTime(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bus.RescanCheckpoint{
+		Rescanned: true,
+		Height:    &height,
+		BlockTime: &blockTime,
+	}, nil
+}
+
+// maxRPCBatchSize is the maximum number of calls in a CallRPC batch.
+const maxRPCBatchSize = 100
+
+// CallRPC issues the given raw RPC calls to the node, in order, and returns
+// their results. Errors returned by the node for a call are reported in its
+// result, while other errors abort the batch.
+//
+// The batch is rejected as a whole, before any call is issued, if one of the
+// methods is not allowlisted.
+func (s *Service) CallRPC(calls []types.RPCCall) ([]types.RPCResult, error) {
+	if len(calls) > maxRPCBatchSize {
+		return nil, fmt.Errorf("%w: %d calls, at most %d allowed",
+			bus.ErrBatchTooLarge, len(calls), maxRPCBatchSize)
+	}
+
+	for _, call := range calls {
+		if !s.rpcAllowlist[call.Method] {
+			return nil, fmt.Errorf("%w: %s", bus.ErrRPCMethodNotAllowed, call.Method)
+		}
+	}
+
+	results := make([]types.RPCResult, 0, len(calls))
+	for _, call := range calls {
+		result, err := s.Bus.CallRPC(call.Method, call.Params)
+
+		var rpcErr *btcjson.RPCError
+		switch {
+		case errors.As(err, &rpcErr):
+			results = append(results, types.RPCResult{Error: rpcErr})
+		case err != nil:
+			return nil, err
+		default:
+			results = append(results, types.RPCResult{Result: result})
+		}
+	}
+
+	return results, nil
+}
+
+// logLevels lists the log levels that can be set at runtime.
+var logLevels = map[log.Level]bool{
+	log.DebugLevel: true,
+	log.InfoLevel:  true,
+	log.WarnLevel:  true,
+	log.ErrorLevel: true,
+}
+
+// SetLogLevel changes the level of the logger without a restart, and returns
+// the previous one.
+//
+// Levels are parsed case-insensitively, and "warning" is accepted as well as
+// "warn", so that the previous level can always be set again.
+func (s *Service) SetLogLevel(level string) (string, error) {
+	newLevel, err := log.ParseLevel(level)
+	if err != nil || !logLevels[newLevel] {
+		return "", fmt.Errorf("%w: %s", bus.ErrInvalidLogLevel, level)
+	}
+
+	previous := log.GetLevel()
+	log.SetLevel(newLevel)
+
+	return previous.String(), nil
+}
@@ -2,62 +2,193 @@ package svc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/ledgerhq/satstack/bus"
+	"github.com/ledgerhq/satstack/types"
 	"github.com/ledgerhq/satstack/version"
 	log "github.com/sirupsen/logrus"
 )
 
+// GetHealth checks the node against the health thresholds, and returns an
+// error if it is unreachable, or degraded.
 func (s *Service) GetHealth() error {
-	info, err := s.Bus.GetBlockChainInfo()
+	return s.Bus.CheckHealth(s.healthThresholds)
+}
+
+// GetFees returns the fee estimates for each of the given confirmation
+// targets, in the given unit.
+//
+// The mode and unit are validated before issuing any request to the node, so
+// that invalid values do not fail midway through the targets.
+func (s *Service) GetFees(targets []int64, mode string, unit string) (map[string]interface{}, error) {
+	key := fmt.Sprintf("fees/%v/%s/%s", targets, mode, unit)
+	if fees, found := s.cachedResponse(key, s.feesTTL); found {
+		return fees.(map[string]interface{}), nil
+	}
+
+	fees, err := s.getFees(targets, mode, unit)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// A nil blockchainInfo indicates that the node is not yet ready.
-	if info == nil {
-		return nil
+	s.cacheResponse(key, s.feesTTL, fees)
+	return fees, nil
+}
+
+func (s *Service) getFees(targets []int64, mode string, unit string) (map[string]interface{}, error) {
+	mode, err := bus.ParseFeeMode(mode)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: Check contents of GetBlockChainInfo response
+	feeUnit, err := bus.ParseFeeUnit(unit)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
-}
+	// Smoothed fees are averaged as of the same height for all the targets,
+	// and fall back to the latest CONSERVATIVE estimates without it.
+	var height int64
+	if mode == bus.SmoothedFeeMode {
+		if height, err = s.Bus.GetBlockCount(); err != nil {
+			mode = "CONSERVATIVE"
+		}
+	}
 
-func (s *Service) GetFees(targets []int64, mode string) map[string]interface{} {
 	result := make(map[string]interface{})
 	for _, target := range targets {
-		fee := s.Bus.EstimateSmartFee(target, mode)
-		result[strconv.FormatInt(target, 10)] = fee
+		var fee types.FeeRate
+		switch mode {
+		case bus.SmoothedFeeMode:
+			fee = types.FeeRate(s.Bus.SmoothedFee(target, height))
+		default:
+			fee = types.FeeRate(s.Bus.EstimateSmartFee(target, mode))
+		}
+
+		switch feeUnit {
+		case bus.SatPerVByte:
+			result[strconv.FormatInt(target, 10)] = fee.SatPerVByte()
+		default:
+			result[strconv.FormatInt(target, 10)] = fee.SatPerKB()
+		}
 	}
 
 	result["last_updated"] = int32(time.Now().Unix())
-	return result
+	return result, nil
+}
+
+// GetPriorityFee returns the fee rate to get a transaction confirmed in the
+// next block, in sat/vB.
+//
+// It is based on the CONSERVATIVE estimate for the shortest confirmation
+// target, floored at the mempool minimum fee of the node. The result is
+// rounded up to the next whole sat/vB.
+func (s *Service) GetPriorityFee() (*bus.PriorityFee, error) {
+	fee := s.Bus.EstimateSmartFee(1, "CONSERVATIVE")
+
+	minFee, err := s.Bus.MempoolMinFee()
+	if err != nil {
+		return nil, err
+	}
+
+	if fee < minFee {
+		fee = minFee
+	}
+
+	return &bus.PriorityFee{
+		FeeRate:     float64(types.FeeRate(fee).CeilSatPerVByte()),
+		LastUpdated: int32(time.Now().Unix()),
+	}, nil
+}
+
+// GetDustThreshold returns the dust limit of outputs of the given script
+// type.
+func (s *Service) GetDustThreshold(scriptType string) (*types.DustThreshold, error) {
+	threshold, err := s.Bus.DustThreshold(scriptType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DustThreshold{
+		ScriptType: strings.ToLower(scriptType),
+		Threshold:  threshold,
+	}, nil
 }
 
 func (s *Service) GetStatus() *bus.ExplorerStatus {
+	if status, found := s.cachedResponse("status", s.statusTTL); found {
+		return status.(*bus.ExplorerStatus)
+	}
+
+	status := s.withGracePeriod(s.getStatus())
+	s.cacheResponse("status", s.statusTTL, status)
+	return status
+}
+
+// withGracePeriod returns the last known good status, marked as stale,
+// instead of a NodeDisconnected status, if the node has been unreachable for
+// less than the status grace period. The grace period restarts after the
+// first successful status.
+func (s *Service) withGracePeriod(status *bus.ExplorerStatus) *bus.ExplorerStatus {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	if status.Status != bus.NodeDisconnected {
+		s.lastGoodStatus = status
+		s.disconnectedSince = time.Time{}
+		return status
+	}
+
+	if s.disconnectedSince.IsZero() {
+		s.disconnectedSince = time.Now()
+	}
+
+	if s.lastGoodStatus == nil || time.Since(s.disconnectedSince) >= s.statusGracePeriod {
+		return status
+	}
+
+	log.WithField(
+		"disconnectedFor", time.Since(s.disconnectedSince).String(),
+	).Warn("Node unreachable, returning the last known good status")
+
+	stale := *s.lastGoodStatus
+	stale.Stale = true
+	return &stale
+}
+
+func (s *Service) getStatus() *bus.ExplorerStatus {
 	// Prepare base bus.ExplorerStatus instance.
 	status := bus.ExplorerStatus{
-		Version:  version.Version,
-		TxIndex:  s.Bus.TxIndex,
-		Pruned:   s.Bus.Pruned,
-		Chain:    s.Bus.Chain,
-		Currency: s.Bus.Currency,
+		Version:      version.Version,
+		TxIndex:      s.Bus.TxIndex,
+		Pruned:       s.Bus.Pruned,
+		Chain:        s.Bus.Chain,
+		Currency:     s.Bus.Currency,
+		WalletFormat: s.Bus.WalletFormat,
+	}
+
+	if drift := s.Bus.DescriptorDrift(); drift != nil && !drift.IsEmpty() {
+		status.DescriptorDrift = drift
 	}
 
 	// Case 1: satstack is running the numbers.
 	// or rescanning the wallet
 	if s.Bus.IsPendingScan {
 		status.Status = bus.PendingScan
+		if scan := s.Bus.ImportProgress(); scan != nil {
+			status.SetScanProgress(scan)
+		}
 		return &status
 	}
 
 	// Case 2: Unable to initialize rpcclient.Client.
-	client, err := s.Bus.ClientFactory()
+	client, err := s.Bus.AcquireClient()
 	if err != nil {
 		log.WithField(
 			"err", fmt.Errorf("%s: %w", bus.ErrBitcoindUnreachable, err),
@@ -66,19 +197,29 @@ func (s *Service) GetStatus() *bus.ExplorerStatus {
 		return &status
 	}
 
-	defer client.Shutdown()
+	defer s.Bus.ReleaseClient(client)
 
 	// Case 3: bitcoind is unreachable - chain RPC failed.
 	// Custom blockchain info struct to avoid btcd struct incompatibility
 	type customBlockChainInfo struct {
 		Blocks               int32   `json:"blocks"`
 		Headers              int32   `json:"headers"`
+		BestBlockHash        string  `json:"bestblockhash"`
+		Time                 int64   `json:"time"` // Since Bitcoin Core v23
 		VerificationProgress float64 `json:"verificationprogress"`
 		Warnings             []string `json:"warnings"`
 	}
 
 	result, err := client.RawRequest("getblockchaininfo", nil)
 	if err != nil {
+		// Case 3a: bitcoind is reachable, but rejected the credentials.
+		if bus.IsAuthError(err) {
+			log.WithField("err", err).Error("RPC authentication failed")
+
+			status.Status = bus.AuthFailed
+			return &status
+		}
+
 		log.WithField(
 			"err", fmt.Errorf("%s: %w", bus.ErrBitcoindUnreachable, err),
 		).Error("Failed to query status")
@@ -97,6 +238,19 @@ func (s *Service) GetStatus() *bus.ExplorerStatus {
 		return &status
 	}
 
+	if tipAge, err := bus.TipAge(client, blockChainInfo.BestBlockHash, blockChainInfo.Time); err != nil {
+		log.WithField("err", err).Warn("Failed to compute tip age")
+	} else {
+		status.TipAgeSeconds = btcjson.Int64(int64(tipAge.Seconds()))
+
+		if tipAge > bus.StaleTipAge {
+			log.WithFields(log.Fields{
+				"tipAge": tipAge,
+				"height": blockChainInfo.Blocks,
+			}).Warn("Chain tip is stale, node may be stalled or have lost its peers")
+		}
+	}
+
 	// Case 4: bitcoind is currently catching up on new blocks.
 	if blockChainInfo.Blocks != blockChainInfo.Headers {
 		status.Status = bus.Syncing
@@ -105,9 +259,35 @@ func (s *Service) GetStatus() *bus.ExplorerStatus {
 		return &status
 	}
 
-	// Case 5: bitcoind is currently importing descriptors
+	behindPeers, err := s.Bus.IsBehindPeers(int64(blockChainInfo.Blocks))
+	if err != nil {
+		log.WithField("err", err).Warn("Failed to compare height with peers")
+	}
+
+	status.BehindPeers = behindPeers
+
+	// Case 5: bitcoind is reachable, but the wallet is not loaded (yet).
+	if s.Bus.IsWalletLoading() {
+		status.Status = bus.WalletLoading
+		return &status
+	}
+
+	// Case 6: bitcoind is currently importing descriptors
 	walletInfo, err := client.GetWalletInfo()
 	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCWalletNotFound {
+			if s.Bus.IsWalletMissing() {
+				status.Status = bus.WalletMissing
+				return &status
+			}
+
+			s.Bus.LoadWalletAsync()
+
+			status.Status = bus.WalletLoading
+			return &status
+		}
+
 		log.WithField(
 			"err", fmt.Errorf("%s: %w", bus.ErrBitcoindUnreachable, err),
 		).Error("Failed to query status")
@@ -119,17 +299,83 @@ func (s *Service) GetStatus() *bus.ExplorerStatus {
 	switch v := walletInfo.Scanning.Value.(type) {
 	case btcjson.ScanProgress:
 		status.Status = bus.Scanning
-		status.ScanProgress = btcjson.Float64(v.Progress * 100)
+		status.SetScanProgress(bus.NewScanProgress(
+			v.Progress, time.Duration(v.Duration)*time.Second))
+		return &status
+	}
+
+	// The import is still in progress, between the scans of two descriptors.
+	if scan := s.Bus.ImportProgress(); scan != nil {
+		status.Status = bus.Scanning
+		status.SetScanProgress(scan)
 		return &status
 	}
 
-	// Case 6: bitcoind is ready to be used with satstack.
+	// Case 7: bitcoind is ready to be used with satstack.
 	status.Status = bus.Ready
 	return &status
 }
 
+// GetSupply returns the result of the circulating supply check performed by
+// the worker on startup.
+func (s *Service) GetSupply() (*types.SupplyReport, error) {
+	supply := s.Bus.Supply()
+	if supply == nil {
+		return nil, bus.ErrSupplyNotComputed
+	}
+
+	return supply, nil
+}
+
+// GetSubsidy returns the block subsidy and halving information at the given
+// height. If height is nil, the next block to be mined is used.
+func (s *Service) GetSubsidy(height *int64) (*types.SubsidyInfo, error) {
+	if height == nil {
+		tip, err := s.Bus.GetBlockCount()
+		if err != nil {
+			return nil, err
+		}
+
+		next := tip + 1
+		height = &next
+	}
+
+	return s.Bus.GetSubsidyInfo(*height)
+}
+
+// GetNetworkStats returns the difficulty, hashrate and next difficulty
+// adjustment estimate of the network.
+func (s *Service) GetNetworkStats() (*types.NetworkStats, error) {
+	return s.Bus.GetNetworkStats()
+}
+
+func (s *Service) GetNodeInfo() (*types.NodeInfo, error) {
+	return s.Bus.GetNodeInfo()
+}
+
+// GetSoftForks returns the status of the soft fork deployments of the chain.
+func (s *Service) GetSoftForks() ([]types.Deployment, error) {
+	return s.Bus.GetSoftForks()
+}
+
+// GetVersion returns the versions of SatStack and of the connected node. The
+// node version is the one detected at startup, so that it can be served
+// without querying the node.
+func (s *Service) GetVersion() *types.VersionInfo {
+	return &types.VersionInfo{
+		Version:        version.Version,
+		GitCommit:      version.GitCommit,
+		Build:          version.Build,
+		BuildTime:      version.BuildTime,
+		GoVersion:      version.GoVersion,
+		OsArch:         version.OsArch,
+		NodeVersion:    s.Bus.NodeVersion,
+		NodeSubversion: s.Bus.NodeSubversion,
+	}
+}
+
 func (s *Service) GetNetwork() (network *bus.Network) {
-	client, err := s.Bus.ClientFactory()
+	client, err := s.Bus.AcquireClient()
 	if err != nil {
 		log.WithField("err", fmt.Errorf("%s: %w", bus.ErrBitcoindUnreachable, err)).
 			Error("Failed to query status")
@@ -138,6 +384,8 @@ func (s *Service) GetNetwork() (network *bus.Network) {
 		return network
 	}
 
+	defer s.Bus.ReleaseClient(client)
+
 	// Custom network info struct to handle warnings as array
 	type customNetworkInfo struct {
 		RelayFee       float64  `json:"relayfee"`
@@ -1,6 +1,9 @@
 package svc
 
 import (
+	"context"
+
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/ledgerhq/satstack/bus"
 	"github.com/ledgerhq/satstack/config"
 	"github.com/ledgerhq/satstack/types"
@@ -9,27 +12,74 @@ import (
 type TransactionsService interface {
 	GetTransaction(hash string, block *types.Block, bestBlockHeight int32) (*types.Transaction, error)
 	GetTransactionHex(hash string) (string, error)
-	SendTransaction(tx string) (string, error)
+	GetRawTransaction(hash string) (string, error)
+	IsOutputSpent(hash string, vout uint32, includeMempool bool) (bool, error)
+	BumpFeeEstimate(hash string, feeRate float64) (*types.BumpFeeResult, error)
+	GetTxMerkleProof(hash string) (*types.MerkleProof, error)
+	SendTransaction(ctx context.Context, tx string) (string, error)
 }
 
 type BlocksService interface {
 	GetBlock(ref string) (*types.Block, error)
+	GetRawBlock(ref string) (string, error)
+	GetBlockStats(ref string) (*types.BlockStats, error)
+	GetLatestBlock() (*types.Block, error)
+	GetBlockFilter(ref string) (*types.BlockFilter, error)
 }
 
 type AddressesService interface {
-	GetAddresses(addresses []string, blockHash *string, blockHeight *int32) (types.Addresses, error)
+	GetAddresses(ctx context.Context, addresses []string, blockHash *string, blockHeight *int32, page *types.HistoryPage) (types.Addresses, error)
+	GetUTXOs(addresses []string, minConf int) ([]types.UnspentOutput, error)
+	GetBalance(addresses []string, minConf int) (*types.Balance, error)
+	GetBalances(addresses []string, minConf int) (map[string]types.Balance, error)
+	GetXPubBalance(xpub string, scheme string, gapLimit int, minConf int) (*types.XPubBalance, error)
+}
+
+type WalletService interface {
+	GetWalletTransactionsSince(since string) (*types.WalletSyncResult, error)
+	GetAccountSummary(descriptors []string) (*types.AccountSummary, error)
+	GetTransactionsInRange(descriptors []string, startHeight int64, endHeight int64) ([]types.Transaction, error)
+	GetNextUnusedAddress(externalDescriptor string) (*types.ReceiveAddress, error)
+	SelectCoins(descriptors []string, target btcutil.Amount, feeRate float64, minConf int) (*types.CoinSelection, error)
 }
 
 type ExplorerService interface {
-	GetFees(targets []int64, mode string) map[string]interface{}
+	GetFees(targets []int64, mode string, unit string) (map[string]interface{}, error)
 	GetHealth() error
+	GetPriorityFee() (*bus.PriorityFee, error)
+	GetDustThreshold(scriptType string) (*types.DustThreshold, error)
 	GetNetwork() *bus.Network
+	GetNetworkStats() (*types.NetworkStats, error)
+	GetNodeInfo() (*types.NodeInfo, error)
+	GetSoftForks() ([]types.Deployment, error)
 	GetStatus() *bus.ExplorerStatus
+	GetSupply() (*types.SupplyReport, error)
+	GetSubsidy(height *int64) (*types.SubsidyInfo, error)
+	GetVersion() *types.VersionInfo
+}
+
+type MempoolService interface {
+	GetMempoolInfo() (*types.MempoolInfo, error)
+	GetMempoolEntry(hash string) (*types.MempoolEntry, error)
+	TestPackageAccept(rawTxHexes []string) (*types.PackageAcceptResult, error)
 }
 
 type ControlService interface {
 	HasDescriptor(descriptor string) (bool, error)
+	GetDescriptorInfo(descriptor string) (*types.DescriptorInfo, error)
+	DeriveAddressAt(descriptor string, index int) (*types.DerivedAddress, error)
+	ExportDescriptors() ([]string, error)
+	ScanTxOutSet(descriptors []string) (*types.ScanResult, error)
 	ImportAccounts(accounts []config.Account)
+	PreviewImport(accounts []config.Account) (*bus.ImportPreview, error)
+	ReimportAccount(label string) error
+	AbortRescan() (bool, error)
+	GetRescanCheckpoint() (*bus.RescanCheckpoint, error)
+	RescanNeeded() ([]string, error)
+	GetPeers(offset int, limit int) (*types.Peers, error)
+	GetChainTips() ([]types.ChainTip, error)
+	CallRPC(calls []types.RPCCall) ([]types.RPCResult, error)
+	SetLogLevel(level string) (string, error)
 }
 
 type ServiceInterface interface {
@@ -37,5 +87,7 @@ type ServiceInterface interface {
 	BlocksService
 	ControlService
 	ExplorerService
+	MempoolService
 	TransactionsService
+	WalletService
 }
@@ -0,0 +1,25 @@
+package svc
+
+import (
+	"strings"
+
+	"github.com/ledgerhq/satstack/types"
+)
+
+// GetMempoolInfo is a service method to get the size of the mempool, and the
+// minimum fee rates enforced by the node.
+func (s *Service) GetMempoolInfo() (*types.MempoolInfo, error) {
+	return s.Bus.GetMempoolInfo()
+}
+
+// GetMempoolEntry is a service method to get the mempool data of an
+// unconfirmed transaction.
+func (s *Service) GetMempoolEntry(hash string) (*types.MempoolEntry, error) {
+	return s.Bus.GetMempoolEntry(strings.TrimPrefix(hash, "0x"))
+}
+
+// TestPackageAccept is a service method to test whether a package of raw
+// transactions would be accepted in the mempool, without broadcasting it.
+func (s *Service) TestPackageAccept(rawTxHexes []string) (*types.PackageAcceptResult, error) {
+	return s.Bus.TestPackageAccept(rawTxHexes)
+}
@@ -1,7 +1,106 @@
 package svc
 
-import "github.com/ledgerhq/satstack/bus"
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ledgerhq/satstack/bus"
+	"github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
 
 type Service struct {
 	Bus *bus.Bus
+
+	// Cache of frequently polled responses, nil if disabled.
+	responses *cache.Cache
+
+	// Time during which cached responses are served, per endpoint. Zero
+	// disables caching for the endpoint.
+	statusTTL time.Duration
+	feesTTL   time.Duration
+
+	// Conditions under which GetHealth reports the node as degraded.
+	healthThresholds bus.HealthThresholds
+
+	// RPC methods that can be called through CallRPC.
+	rpcAllowlist map[string]bool
+
+	// Time during which GetStatus returns the last known good status, while
+	// the node is unreachable.
+	statusGracePeriod time.Duration
+
+	// statusMu guards lastGoodStatus and disconnectedSince.
+	statusMu          sync.Mutex
+	lastGoodStatus    *bus.ExplorerStatus
+	disconnectedSince time.Time
+}
+
+// SetStatusGracePeriod sets the time during which GetStatus keeps returning
+// the last known good status, marked as stale, while the node is unreachable.
+// This prevents the status from flapping on brief network blips. Zero
+// disables the grace period.
+func (s *Service) SetStatusGracePeriod(gracePeriod time.Duration) {
+	s.statusGracePeriod = gracePeriod
+}
+
+// SetHealthThresholds sets the conditions under which GetHealth reports the
+// node as degraded. Until called, the thresholds are all disabled.
+func (s *Service) SetHealthThresholds(thresholds bus.HealthThresholds) {
+	s.healthThresholds = thresholds
+}
+
+// EnableResponseCache enables serving the responses of GetStatus and GetFees
+// from memory, for the given durations after they were computed.
+//
+// These are polled frequently by clients, while the underlying data only
+// changes every few seconds (status) or every block (fees).
+func (s *Service) EnableResponseCache(statusTTL time.Duration, feesTTL time.Duration) {
+	s.statusTTL = statusTTL
+	s.feesTTL = feesTTL
+	s.responses = cache.New(cache.NoExpiration, time.Minute)
+}
+
+// cachedResponse returns the cached response for key, if caching is enabled
+// for the given TTL, and the response has not expired.
+func (s *Service) cachedResponse(key string, ttl time.Duration) (interface{}, bool) {
+	if s.responses == nil || ttl <= 0 {
+		return nil, false
+	}
+
+	return s.responses.Get(key)
+}
+
+// cacheResponse stores a response for key, if caching is enabled for the
+// given TTL.
+func (s *Service) cacheResponse(key string, ttl time.Duration, response interface{}) {
+	if s.responses == nil || ttl <= 0 {
+		return
+	}
+
+	s.responses.Set(key, response, ttl)
+}
+
+// SetRPCAllowlist sets the RPC methods that can be called through CallRPC.
+// Until called, no method is allowed.
+//
+// Methods that can never be called through the passthrough are ignored, with
+// a warning.
+func (s *Service) SetRPCAllowlist(methods []string) {
+	s.rpcAllowlist = make(map[string]bool, len(methods))
+
+	for _, method := range methods {
+		method = strings.ToLower(strings.TrimSpace(method))
+		if method == "" {
+			continue
+		}
+
+		if bus.IsRPCMethodDenied(method) {
+			log.WithField("method", method).Warn("Ignoring dangerous RPC method in allowlist")
+			continue
+		}
+
+		s.rpcAllowlist[method] = true
+	}
 }
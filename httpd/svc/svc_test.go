@@ -0,0 +1,90 @@
+package svc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ledgerhq/satstack/bus"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestSetRPCAllowlistDropsDeniedMethods(t *testing.T) {
+	denied := []string{
+		"stop", "dumpprivkey", "importdescriptors", "sendtoaddress",
+		"setlabel", "walletlock", "newkeypool", "importprunedfunds",
+		"removeprunedfunds", "dumptxoutset", "loadtxoutset", "savemempool",
+		"importmempool", "scantxoutset", "generatetoaddress", "generateblock",
+		"generatetodescriptor", "reconsiderblock", "prioritisetransaction",
+		"getnewaddress", "getrawchangeaddress", "addmultisigaddress",
+		"walletcreatefundedpsbt", "verifychain", "waitfornewblock",
+		"waitforblock", "waitforblockheight",
+	}
+
+	for _, method := range denied {
+		if !bus.IsRPCMethodDenied(method) {
+			t.Errorf("IsRPCMethodDenied(%q) = false, want true", method)
+		}
+	}
+
+	// Denied methods are dropped regardless of their case or spacing.
+	methods := []string{"getblockcount", " GetBestBlockHash "}
+	for _, method := range denied {
+		methods = append(methods, method, " "+strings.ToUpper(method))
+	}
+
+	var s Service
+	s.SetRPCAllowlist(methods)
+
+	for _, method := range denied {
+		if s.rpcAllowlist[method] {
+			t.Errorf("SetRPCAllowlist() allowed denied method %q", method)
+		}
+	}
+
+	for _, method := range []string{"getblockcount", "getbestblockhash"} {
+		if !s.rpcAllowlist[method] {
+			t.Errorf("SetRPCAllowlist() did not allow %q", method)
+		}
+	}
+
+	if len(s.rpcAllowlist) != 2 {
+		t.Errorf("SetRPCAllowlist() allowed %d methods, want 2", len(s.rpcAllowlist))
+	}
+}
+
+func TestDefaultRPCAllowlistNotDenied(t *testing.T) {
+	for _, method := range bus.DefaultRPCAllowlist {
+		if bus.IsRPCMethodDenied(method) {
+			t.Errorf("default allowlist contains denied method %q", method)
+		}
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer log.SetLevel(log.GetLevel())
+
+	var s Service
+
+	log.SetLevel(log.WarnLevel)
+
+	// The previous level, as reported, can be set again.
+	previous, err := s.SetLogLevel("DEBUG")
+	if err != nil {
+		t.Fatalf("SetLogLevel(DEBUG) error = %v", err)
+	}
+
+	if _, err := s.SetLogLevel(previous); err != nil {
+		t.Fatalf("SetLogLevel(%s) error = %v", previous, err)
+	}
+
+	if log.GetLevel() != log.WarnLevel {
+		t.Errorf("log level = %s, want %s", log.GetLevel(), log.WarnLevel)
+	}
+
+	for _, level := range []string{"", "trace", "fatal", "panic", "verbose"} {
+		if _, err := s.SetLogLevel(level); !errors.Is(err, bus.ErrInvalidLogLevel) {
+			t.Errorf("SetLogLevel(%q) error = %v, want %v", level, err, bus.ErrInvalidLogLevel)
+		}
+	}
+}
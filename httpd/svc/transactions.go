@@ -1,8 +1,11 @@
 package svc
 
 import (
+	"context"
+	"strings"
 	"time"
 
+	"github.com/ledgerhq/satstack/protocol"
 	"github.com/ledgerhq/satstack/types"
 	"github.com/ledgerhq/satstack/utils"
 
@@ -25,10 +28,30 @@ func (s *Service) GetTransaction(hash string, block *types.Block, bestBlockHeigh
 
 	tx.Block = block
 	buildTx(tx, utxos, bestBlockHeight)
+	tx.Replaceable = s.isReplaceable(tx)
 
 	return tx, nil
 }
 
+// isReplaceable reports whether an unconfirmed transaction can be replaced
+// by a transaction paying a higher fee, as defined by BIP125.
+//
+// Confirmed transactions are never replaceable. For transactions in the
+// mempool, the node is queried, since replaceability can be inherited from
+// unconfirmed ancestors. Otherwise, the input sequence numbers are used.
+func (s *Service) isReplaceable(tx *types.Transaction) bool {
+	if tx.Block != nil && tx.Block.Height >= 0 {
+		return false
+	}
+
+	entry, err := s.Bus.GetMempoolEntry(tx.Hash)
+	if err != nil {
+		return protocol.SignalsRBF(tx.Inputs)
+	}
+
+	return entry.BIP125Replaceable
+}
+
 // GetTransactionHex is a service function to get hex encoded raw
 // transaction by hash.
 func (s *Service) GetTransactionHex(hash string) (string, error) {
@@ -40,8 +63,37 @@ func (s *Service) GetTransactionHex(hash string) (string, error) {
 	return s.Bus.GetTransactionHex(chainHash)
 }
 
-func (s *Service) SendTransaction(tx string) (string, error) {
-	hash, err := s.Bus.SendTransaction(tx)
+// GetRawTransaction is a service function to get the hex encoded raw
+// transaction by hash, whether or not it belongs to the wallet.
+func (s *Service) GetRawTransaction(hash string) (string, error) {
+	chainHash, err := utils.ParseChainHash(hash)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Bus.GetRawTransaction(chainHash.String())
+}
+
+// IsOutputSpent is a service function to check whether an output of a
+// transaction, by hash and index, is spent.
+func (s *Service) IsOutputSpent(hash string, vout uint32, includeMempool bool) (bool, error) {
+	return s.Bus.IsOutputSpent(strings.TrimPrefix(hash, "0x"), vout, includeMempool)
+}
+
+// BumpFeeEstimate is a service function to compute the fee required to
+// replace an unconfirmed transaction at the given fee rate, in sat/vB.
+func (s *Service) BumpFeeEstimate(hash string, feeRate float64) (*types.BumpFeeResult, error) {
+	return s.Bus.BumpFeeEstimate(strings.TrimPrefix(hash, "0x"), feeRate)
+}
+
+// GetTxMerkleProof is a service function to get a proof of inclusion of a
+// confirmed transaction, by hash.
+func (s *Service) GetTxMerkleProof(hash string) (*types.MerkleProof, error) {
+	return s.Bus.GetTxMerkleProof(hash)
+}
+
+func (s *Service) SendTransaction(ctx context.Context, tx string) (string, error) {
+	hash, err := s.Bus.SendTransaction(ctx, tx)
 	if err != nil {
 		return "", err
 	}
@@ -80,6 +132,33 @@ func (s *Service) buildUTXOs(vin []types.Input) (types.UTXOs, error) {
 	return utxoMap, nil
 }
 
+// txConfirmations returns the number of confirmations of a transaction
+// included in the given block, relative to the height of the chain tip. The
+// tip height must be read once per request, so that all the transactions of
+// a response share the same baseline.
+//
+// Unconfirmed transactions have 0 confirmations, as do transactions of blocks
+// above the tip, which were mined after the tip was read.
+func txConfirmations(block *types.Block, tipHeight int64) int64 {
+	if block == nil || block.Height < 0 || block.Height > tipHeight {
+		return 0
+	}
+
+	return tipHeight - block.Height + 1
+}
+
+// walletTxConfirmations returns the number of confirmations of a wallet
+// transaction, given the confirmations reported by the wallet. Transactions
+// that the wallet reports as conflicting with the active chain, with negative
+// confirmations, are orphaned. Others are counted with txConfirmations.
+func walletTxConfirmations(walletConfirmations int64, block *types.Block, tipHeight int64) int64 {
+	if walletConfirmations < 0 {
+		return types.OrphanedConfirmations
+	}
+
+	return txConfirmations(block, tipHeight)
+}
+
 func buildTx(tx *types.Transaction, utxoMap types.UTXOs, bestBlockHeight int32) {
 	sumVinValues := btcutil.Amount(0)
 	vinHasCoinbase := false
@@ -109,12 +188,12 @@ func buildTx(tx *types.Transaction, utxoMap types.UTXOs, bestBlockHeight int32)
 		sumVoutValues += *vout.Value
 	}
 
-	if tx.Block != nil {
-		tx.Confirmations = uint64(int64(bestBlockHeight)-tx.Block.Height) + 1
+	tx.Confirmations = txConfirmations(tx.Block, int64(bestBlockHeight))
+
+	if tx.Block != nil && tx.Block.Height >= 0 {
 		tx.ReceivedAt = tx.Block.Time
 	} else {
 		// Handle the case of unconfirmed transaction.
-		tx.Confirmations = 0
 		tx.ReceivedAt = utils.ParseUnixTimestamp(time.Now().Unix())
 	}
 
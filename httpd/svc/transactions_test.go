@@ -0,0 +1,72 @@
+package svc
+
+import (
+	"testing"
+
+	"github.com/ledgerhq/satstack/types"
+)
+
+func TestTxConfirmations(t *testing.T) {
+	const tip = 100
+
+	tests := []struct {
+		name  string
+		block *types.Block
+		want  int64
+	}{
+		{name: "unconfirmed", block: nil, want: 0},
+		{name: "mempool", block: &types.Block{Height: -1}, want: 0},
+		{name: "negative height", block: &types.Block{Height: -42}, want: 0},
+		{name: "genesis", block: &types.Block{Height: 0}, want: tip + 1},
+		{name: "below tip", block: &types.Block{Height: 90}, want: 11},
+		{name: "tip", block: &types.Block{Height: tip}, want: 1},
+		{name: "above tip", block: &types.Block{Height: tip + 1}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := txConfirmations(tt.block, tip); got != tt.want {
+				t.Errorf("txConfirmations(%+v, %d) = %d, want %d", tt.block, tip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletTxConfirmations(t *testing.T) {
+	const tip = 100
+
+	tests := []struct {
+		name                string
+		walletConfirmations int64
+		block               *types.Block
+		want                int64
+	}{
+		{name: "confirmed", walletConfirmations: 11, block: &types.Block{Height: 90}, want: 11},
+		{name: "unconfirmed", walletConfirmations: 0, block: &types.Block{Height: -1}, want: 0},
+		{name: "above tip", walletConfirmations: 1, block: &types.Block{Height: tip + 1}, want: 0},
+		{
+			name:                "orphaned",
+			walletConfirmations: types.OrphanedConfirmations,
+			block:               &types.Block{Height: -1},
+			want:                types.OrphanedConfirmations,
+		},
+		{
+			// The block of a transaction conflicting with the active chain
+			// must not give it positive confirmations.
+			name:                "orphaned in stale block",
+			walletConfirmations: -3,
+			block:               &types.Block{Hash: "stale", Height: 95},
+			want:                types.OrphanedConfirmations,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := walletTxConfirmations(tt.walletConfirmations, tt.block, tip)
+			if got != tt.want {
+				t.Errorf("walletTxConfirmations(%d, %+v, %d) = %d, want %d",
+					tt.walletConfirmations, tt.block, tip, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package svc
+
+import (
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/ledgerhq/satstack/types"
+	"github.com/ledgerhq/satstack/utils"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// GetWalletTransactionsSince is a service method to get the wallet
+// transactions since the given block hash, or all of them if since is empty.
+func (s *Service) GetWalletTransactionsSince(since string) (*types.WalletSyncResult, error) {
+	var blockHash *chainhash.Hash
+	if since != "" {
+		var err error
+		blockHash, err = utils.ParseChainHash(since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Snapshot the tip before listing the transactions, so that those
+	// confirmed in the meantime are reported as unconfirmed, rather than
+	// with inconsistent confirmations.
+	bestBlockHeight, err := s.Bus.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.Bus.GetWalletTransactionsSince(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range result.Transactions {
+		tx := &result.Transactions[idx]
+
+		utxos, err := s.buildUTXOs(tx.Inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		// The Bus only reports whether the transaction is orphaned, which
+		// buildTx would override.
+		walletConfirmations := tx.Confirmations
+
+		buildTx(tx, utxos, int32(bestBlockHeight))
+		tx.Replaceable = s.isReplaceable(tx)
+		tx.Confirmations = walletTxConfirmations(walletConfirmations, tx.Block, bestBlockHeight)
+	}
+
+	return result, nil
+}
+
+// GetAccountSummary is a service method to get the received and sent totals
+// of the account made of the given descriptors.
+func (s *Service) GetAccountSummary(descriptors []string) (*types.AccountSummary, error) {
+	return s.Bus.GetAccountSummary(descriptors)
+}
+
+// GetTransactionsInRange is a service method to get the transactions of an
+// account confirmed within a range of block heights.
+func (s *Service) GetTransactionsInRange(descriptors []string, startHeight int64, endHeight int64) ([]types.Transaction, error) {
+	txs, err := s.Bus.GetTransactionsInRange(descriptors, startHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	tipHeight, err := s.Bus.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range txs {
+		txs[idx].Confirmations = txConfirmations(txs[idx].Block, tipHeight)
+	}
+
+	return txs, nil
+}
+
+// GetNextUnusedAddress is a service method to get the first unused receive
+// address of an account, identified by its external descriptor.
+func (s *Service) GetNextUnusedAddress(externalDescriptor string) (*types.ReceiveAddress, error) {
+	address, index, err := s.Bus.GetNextUnusedAddress(externalDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ReceiveAddress{Address: address, Index: index}, nil
+}
+
+// SelectCoins is a service method to preview the UTXOs of an account that
+// would be spent to pay the target amount at the given fee rate.
+func (s *Service) SelectCoins(descriptors []string, target btcutil.Amount, feeRate float64, minConf int) (*types.CoinSelection, error) {
+	return s.Bus.SelectCoins(descriptors, target, feeRate, minConf)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/ledgerhq/satstack/utils"
 
@@ -48,6 +50,7 @@ func ParseVerboseTransaction(txRaw *btcjson.TxRawResult) *types.Transaction {
 			Value:       &val,
 			ScriptHex:   output.ScriptPubKey.Hex,
 			Address:     addr,
+			ScriptType:  types.ParseScriptType(output.ScriptPubKey.Type),
 		})
 	}
 
@@ -93,6 +96,25 @@ func DecodeRawTransaction(txnHex string, params *chaincfg.Params) (*types.Transa
 	return DecodeMsgTx(&mtx, params), nil
 }
 
+// maxRBFSequence is the maximum input sequence number that signals opt-in
+// replaceability, as defined by BIP125.
+const maxRBFSequence = wire.MaxTxInSequenceNum - 2
+
+// SignalsRBF reports whether any of the given inputs explicitly signals
+// opt-in replaceability, as defined by BIP125.
+//
+// It does not account for replaceability inherited from unconfirmed
+// ancestors. Use the bip125-replaceable field of getmempoolentry for that.
+func SignalsRBF(inputs []types.Input) bool {
+	for _, input := range inputs {
+		if input.Sequence <= maxRBFSequence {
+			return true
+		}
+	}
+
+	return false
+}
+
 // createVinList returns a slice of JSON objects for the inputs of the passed
 // transaction.
 func createVinList(mtx *wire.MsgTx) []types.Input {
@@ -139,9 +161,13 @@ func createVoutList(mtx *wire.MsgTx, chainParams *chaincfg.Params) []types.Outpu
 
 		// Ignore the error here since an error means the script
 		// couldn't parse. In such a case, addrs will be nil.
-		_, addrs, _, _ := txscript.ExtractPkScriptAddrs(
+		class, addrs, _, _ := txscript.ExtractPkScriptAddrs(
 			v.PkScript, chainParams)
 
+		// The names of the btcd script classes are the same as the
+		// scriptPubKey types of Bitcoin Core.
+		vout.ScriptType = types.ParseScriptType(class.String())
+
 		// Encode the addresses to to string.
 		encodedAddrs := make([]string, len(addrs))
 		for j, addr := range addrs {
@@ -162,12 +188,58 @@ func createVoutList(mtx *wire.MsgTx, chainParams *chaincfg.Params) []types.Outpu
 			vout.Address = encodedAddrs[0]
 		}
 
+		// OP_RETURN outputs have no address, but carry data that clients
+		// may want to display, for ex a memo or a commitment.
+		if data, ok := nullData(v.PkScript); ok {
+			dataHex := hex.EncodeToString(data)
+			vout.Unspendable = true
+			vout.DataHex = &dataHex
+
+			if text := string(data); isPrintable(text) {
+				vout.Data = &text
+			}
+		}
+
 		voutList = append(voutList, vout)
 	}
 
 	return voutList
 }
 
+// nullData returns the data carried by an OP_RETURN output script, as the
+// concatenation of the data pushed after OP_RETURN.
+//
+// Non-standard scripts, whose pushes cannot be parsed, are still provably
+// unspendable, in which case the raw bytes following OP_RETURN are returned.
+func nullData(pkScript []byte) ([]byte, bool) {
+	if len(pkScript) == 0 || pkScript[0] != txscript.OP_RETURN {
+		return nil, false
+	}
+
+	pushes, err := txscript.PushedData(pkScript[1:])
+	if err != nil {
+		return pkScript[1:], true
+	}
+
+	return bytes.Join(pushes, nil), true
+}
+
+// isPrintable reports whether a string is non-empty, valid UTF-8 text without
+// control characters.
+func isPrintable(text string) bool {
+	if text == "" || !utf8.ValidString(text) {
+		return false
+	}
+
+	for _, r := range text {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // witnessToHex formats the passed witness stack as a slice of hex-encoded
 // strings to be used in a JSON response.
 func witnessToHex(witness wire.TxWitness) []string {
@@ -0,0 +1,61 @@
+#!/usr/bin/env bash
+
+# Checks that a wallet transaction reorganized out of the chain is reported
+# in the removed field of the wallet transactions endpoint.
+#
+# Requires a regtest bitcoind, and SatStack running against it with at least
+# one account imported in the "satstack" wallet. A "miner" wallet is created
+# on the node to fund the test transaction.
+
+RED='\033[0;31m'
+CYAN='\033[0;36m'
+GREEN='\033[0;32m'
+NC='\033[0m' # No Color
+
+EXPLORER="${EXPLORER:-http://0.0.0.0:20000}"
+CLI="${CLI:-bitcoin-cli -regtest}"
+WALLET="${WALLET:-satstack}"
+
+set -e
+
+$CLI -named createwallet wallet_name=miner load_on_startup=false > /dev/null 2>&1 \
+  || $CLI loadwallet miner > /dev/null 2>&1 || true
+
+miner=$($CLI -rpcwallet=miner getnewaddress)
+$CLI generatetoaddress 101 "$miner" > /dev/null
+
+# Pay the SatStack wallet, and confirm the transaction in a block which is
+# going to be reorganized out.
+address=$($CLI -rpcwallet="$WALLET" getnewaddress)
+txid=$($CLI -rpcwallet=miner -named sendtoaddress address="$address" amount=1 replaceable=true)
+stale=$($CLI generatetoaddress 1 "$miner" | jq -r ".[0]")
+
+echo -e ""
+echo -e "${CYAN}TXID:${NC}           ${txid}"
+echo -e "${CYAN}STALE BLOCK:${NC}    ${stale}"
+
+# Invalidate the block, and replace the transaction while it is back in the
+# mempool, so that it cannot be included again in the new chain.
+$CLI invalidateblock "$stale"
+replacement=$($CLI -rpcwallet=miner bumpfee "$txid" | jq -r ".txid")
+$CLI generatetoaddress 2 "$miner" > /dev/null
+$CLI reconsiderblock "$stale"
+
+echo -e "${CYAN}REPLACEMENT:${NC}    ${replacement}"
+
+# Wait for SatStack to see the new tip.
+sleep 5
+
+got=$(curl -sf "${EXPLORER}/blockchain/v3/btc_testnet/wallet/transactions?since=${stale}")
+removed=$(echo "$got" | jq -r ".removed[]")
+added=$(echo "$got" | jq -r ".transactions[].hash")
+
+if [[ "$removed" == *"$txid"* ]] && [[ "$added" == *"$replacement"* ]]; then
+  echo -e "${CYAN}REMOVED:${NC}        $removed"
+  echo -e "${GREEN}Reorg OK ✅${NC}"
+else
+  echo -e "${RED}WANT:${NC}           removed $txid, added $replacement"
+  echo -e "${RED}GOT:${NC}            $got"
+  echo -e "${RED}Unexpected response ❌${NC}"
+  exit 1
+fi
@@ -0,0 +1,37 @@
+package types
+
+import "github.com/btcsuite/btcd/btcutil"
+
+// AccountSummary models the accounting totals of an account, identified by
+// its descriptors.
+type AccountSummary struct {
+	Received btcutil.Amount `json:"received"` // Total received from outside the account, excluding change
+	Sent     btcutil.Amount `json:"sent"`     // Total sent outside the account, including fees
+	Balance  Balance        `json:"balance"`  // Current balance, including unconfirmed UTXOs
+	TxCount  int            `json:"tx_count"` // Number of transactions involving the account
+}
+
+// CoinSelection models the UTXOs selected to pay a target amount, along with
+// the resulting fee and change, as a preview of an unsigned transaction.
+type CoinSelection struct {
+	Inputs  []UnspentOutput `json:"inputs"`
+	Target  btcutil.Amount  `json:"target"`   // Amount paid to the recipient
+	Fee     btcutil.Amount  `json:"fee"`      // Includes the change if it was below the dust limit
+	Change  btcutil.Amount  `json:"change"`   // 0 if the transaction has no change output
+	FeeRate float64         `json:"fee_rate"` // Requested fee rate, in sat/vB
+	VSize   int64           `json:"vsize"`    // Estimated virtual size of the transaction
+}
+
+// ReceiveAddress models a fresh receive address of an account.
+type ReceiveAddress struct {
+	Address string `json:"address"`
+	Index   int    `json:"index"` // Index of the address in the external descriptor
+}
+
+// XPubBalance models the aggregate balance of an account specified by its
+// extended public key.
+type XPubBalance struct {
+	Descriptors   []string `json:"descriptors"`    // Receive and change descriptors of the account
+	UsedAddresses int      `json:"used_addresses"` // Number of addresses that received funds
+	Balance       Balance  `json:"balance"`
+}
@@ -1,5 +1,7 @@
 package types
 
+import "github.com/btcsuite/btcd/btcutil"
+
 // BlockChainInfo models the data from the getblockchaininfo command.
 //
 // The fields are explicitly defined here because the `softforks` field is
@@ -15,6 +17,7 @@ type BlockChainInfo struct {
 	BestBlockHash        string               `json:"bestblockhash"`
 	Difficulty           float64              `json:"difficulty"`
 	MedianTime           int64                `json:"mediantime"`
+	Time                 int64                `json:"time"` // Since Bitcoin Core v23
 	VerificationProgress float64              `json:"verificationprogress"`
 	InitialBlockDownload bool                 `json:"initialblockdownload"`
 	ChainWork            string               `json:"chainwork"`
@@ -42,3 +45,71 @@ type SoftFork struct {
 type SoftForkReject struct {
 	Status bool `json:"status"`
 }
+
+// BlockFilter models a BIP158 compact block filter, along with its filter
+// header.
+type BlockFilter struct {
+	Filter string `json:"filter"` // Hex-encoded filter data
+	Header string `json:"header"` // Hex-encoded filter header
+}
+
+// MerkleProof models a proof of inclusion of a transaction in a block.
+//
+// Hashes and Flags describe the partial merkle tree, as defined by BIP37.
+// Proof is the serialized merkle block, as returned by gettxoutproof.
+type MerkleProof struct {
+	TxID      string   `json:"txid"`
+	BlockHash string   `json:"block_hash"`
+	Header    string   `json:"header"`   // Hex-encoded 80-byte block header
+	TxCount   uint32   `json:"tx_count"` // Number of transactions in the block
+	Hashes    []string `json:"hashes"`   // Hashes of the partial merkle tree, in depth-first order
+	Flags     string   `json:"flags"`    // Hex-encoded flag bits of the partial merkle tree
+	Proof     string   `json:"proof"`
+}
+
+// DeploymentStatus is the status of a soft fork deployment, as reported by
+// Bitcoin Core.
+type DeploymentStatus string
+
+const (
+	DeploymentDefined  DeploymentStatus = "defined"
+	DeploymentStarted  DeploymentStatus = "started"
+	DeploymentLockedIn DeploymentStatus = "locked_in"
+	DeploymentActive   DeploymentStatus = "active"
+	DeploymentFailed   DeploymentStatus = "failed"
+)
+
+// Deployment models the status of a soft fork deployment, for ex Taproot.
+type Deployment struct {
+	Name   string           `json:"name"`             // ex: taproot, csv, segwit
+	Type   string           `json:"type"`             // buried or bip9
+	Status DeploymentStatus `json:"status"`           // BIP9 status, or active for active buried deployments
+	Active bool             `json:"active"`           // Whether the rules are enforced for the next block
+	Height *int64           `json:"height,omitempty"` // Activation height, if known
+	Since  *int64           `json:"since,omitempty"`  // Height of the first block of the current BIP9 status
+}
+
+// ChainTip models a tip of the block tree known by the node, either the tip
+// of the active chain or of a fork.
+type ChainTip struct {
+	Height    int64  `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen int64  `json:"branch_length"` // 0 for the active chain
+	Status    string `json:"status"`        // active, valid-fork, valid-headers, headers-only or invalid
+}
+
+// BlockStats models per-block statistics, computed by the node from the
+// block and the outputs it spends.
+type BlockStats struct {
+	Hash          string         `json:"hash"`
+	Height        int64          `json:"height"`
+	Time          string         `json:"time"`
+	TxCount       int64          `json:"tx_count"`    // Including the coinbase transaction
+	InputCount    int64          `json:"input_count"` // Excluding the coinbase input
+	OutputCount   int64          `json:"output_count"`
+	TotalFee      btcutil.Amount `json:"total_fee"`
+	Subsidy       btcutil.Amount `json:"subsidy"`
+	AvgFeeRate    int64          `json:"avg_fee_rate"`    // sat/vB
+	MedianFeeRate int64          `json:"median_fee_rate"` // sat/vB
+	SegwitShare   float64        `json:"segwit_share"`    // Share of non-coinbase transactions with witness data, from 0 to 1
+}
@@ -0,0 +1,17 @@
+package types
+
+// DescriptorInfo models how the node interprets an output descriptor.
+type DescriptorInfo struct {
+	Descriptor     string `json:"descriptor"`       // Canonical form, without private keys
+	Checksum       string `json:"checksum"`         // Checksum of the input descriptor
+	IsRange        bool   `json:"is_range"`         // Whether the descriptor is ranged
+	IsSolvable     bool   `json:"is_solvable"`      // Whether the descriptor is solvable
+	HasPrivateKeys bool   `json:"has_private_keys"` // Whether the input has at least one private key
+}
+
+// DerivedAddress models the address at an index of a ranged descriptor.
+type DerivedAddress struct {
+	Descriptor string `json:"descriptor"`
+	Index      int    `json:"index"`
+	Address    string `json:"address"`
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"math"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// FeeRate represents a transaction fee rate in satoshis per kilo-virtual-byte
+// (sat/kvB). This is the same unit as bitcoind fee rates (BTC/kvB), scaled to
+// satoshis, which allows storing it as an integer without loss of precision.
+type FeeRate btcutil.Amount
+
+// FeeRateFromBTCPerKB converts a fee rate in BTC/kvB, as returned by bitcoind
+// RPCs, to a FeeRate.
+func FeeRateFromBTCPerKB(value float64) (FeeRate, error) {
+	amount, err := btcutil.NewAmount(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return FeeRate(amount), nil
+}
+
+// FeeRateFromSatPerVByte converts a fee rate in sat/vB to a FeeRate.
+//
+// Precision beyond 0.001 sat/vB is rounded to the nearest sat/kvB.
+func FeeRateFromSatPerVByte(value float64) FeeRate {
+	return FeeRate(math.Round(value * 1000))
+}
+
+// BTCPerKB returns the fee rate in BTC/kvB.
+func (f FeeRate) BTCPerKB() float64 {
+	return btcutil.Amount(f).ToBTC()
+}
+
+// SatPerKB returns the fee rate in sat/kvB.
+func (f FeeRate) SatPerKB() btcutil.Amount {
+	return btcutil.Amount(f)
+}
+
+// SatPerVByte returns the fee rate in sat/vB, with a precision of 0.001
+// sat/vB.
+func (f FeeRate) SatPerVByte() float64 {
+	return float64(f) / 1000
+}
+
+// CeilSatPerVByte returns the fee rate in sat/vB, rounded up to the next
+// whole sat/vB.
+//
+// Rounding up ensures that a non-zero fee rate below 1 sat/vB never rounds
+// down to 0, and that the resulting rate is never lower than the original.
+func (f FeeRate) CeilSatPerVByte() int64 {
+	return int64(math.Ceil(f.SatPerVByte()))
+}
+
+// BumpFeeResult models the fee required to replace an unconfirmed
+// transaction at a higher fee rate, as defined by BIP125.
+type BumpFeeResult struct {
+	TxID            string         `json:"txid"`
+	VSize           int64          `json:"vsize"`             // Virtual size of the original transaction
+	OriginalFee     btcutil.Amount `json:"original_fee"`      // Fee paid by the original transaction
+	RequiredFee     btcutil.Amount `json:"required_fee"`      // Minimum total fee of the replacement
+	AdditionalFee   btcutil.Amount `json:"additional_fee"`    // RequiredFee - OriginalFee
+	RequiredFeeRate float64        `json:"required_fee_rate"` // RequiredFee / VSize, in sat/vB
+}
+
+// DustThreshold models the smallest non-dust value of an output of a given
+// script type, according to the relay policy of the node.
+type DustThreshold struct {
+	ScriptType string         `json:"script_type"`
+	Threshold  btcutil.Amount `json:"threshold"` // Outputs below this value are dust
+}
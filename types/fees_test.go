@@ -0,0 +1,81 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+func TestFeeRateFromBTCPerKB(t *testing.T) {
+	tests := []struct {
+		btcPerKB    float64
+		satPerKB    btcutil.Amount
+		satPerVByte float64
+	}{
+		{btcPerKB: 0, satPerKB: 0, satPerVByte: 0},
+		{btcPerKB: 0.00000001, satPerKB: 1, satPerVByte: 0.001},
+		{btcPerKB: 0.00001, satPerKB: 1000, satPerVByte: 1},
+		{btcPerKB: 0.00001234, satPerKB: 1234, satPerVByte: 1.234},
+		{btcPerKB: 0.0002, satPerKB: 20000, satPerVByte: 20},
+	}
+
+	for _, tt := range tests {
+		fee, err := FeeRateFromBTCPerKB(tt.btcPerKB)
+		if err != nil {
+			t.Fatalf("FeeRateFromBTCPerKB(%v) error = %v", tt.btcPerKB, err)
+		}
+
+		if fee.SatPerKB() != tt.satPerKB {
+			t.Errorf("FeeRateFromBTCPerKB(%v).SatPerKB() = %d, want %d", tt.btcPerKB, fee.SatPerKB(), tt.satPerKB)
+		}
+
+		if fee.SatPerVByte() != tt.satPerVByte {
+			t.Errorf("FeeRateFromBTCPerKB(%v).SatPerVByte() = %v, want %v", tt.btcPerKB, fee.SatPerVByte(), tt.satPerVByte)
+		}
+
+		if fee.BTCPerKB() != tt.btcPerKB {
+			t.Errorf("FeeRateFromBTCPerKB(%v).BTCPerKB() = %v, want %v", tt.btcPerKB, fee.BTCPerKB(), tt.btcPerKB)
+		}
+	}
+}
+
+func TestCeilSatPerVByte(t *testing.T) {
+	tests := []struct {
+		fee  FeeRate
+		want int64
+	}{
+		{fee: 0, want: 0},
+		{fee: 1, want: 1},   // 0.001 sat/vB
+		{fee: 999, want: 1}, // 0.999 sat/vB
+		{fee: 1000, want: 1},
+		{fee: 1001, want: 2},
+		{fee: 20000, want: 20},
+	}
+
+	for _, tt := range tests {
+		if got := tt.fee.CeilSatPerVByte(); got != tt.want {
+			t.Errorf("FeeRate(%d).CeilSatPerVByte() = %d, want %d", tt.fee, got, tt.want)
+		}
+	}
+}
+
+func TestFeeRateFromSatPerVByte(t *testing.T) {
+	tests := []struct {
+		satPerVByte float64
+		want        FeeRate
+	}{
+		{satPerVByte: 0, want: 0},
+		{satPerVByte: 0.001, want: 1},
+		{satPerVByte: 0.0004, want: 0},
+		{satPerVByte: 0.0005, want: 1},
+		{satPerVByte: 1, want: 1000},
+		{satPerVByte: 1.2345, want: 1235},
+		{satPerVByte: 1.2344, want: 1234},
+	}
+
+	for _, tt := range tests {
+		if got := FeeRateFromSatPerVByte(tt.satPerVByte); got != tt.want {
+			t.Errorf("FeeRateFromSatPerVByte(%v) = %d, want %d", tt.satPerVByte, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// UnconfirmedHeight is the sort height of unconfirmed transactions in a
+// paginated history, so that they come before all confirmed ones.
+const UnconfirmedHeight = math.MaxInt64
+
+// HistoryCursor identifies the last transaction of a page of history.
+//
+// Pages are ordered by confirmation height descending, then by transaction
+// ID, which keeps the order stable as new transactions are confirmed.
+type HistoryCursor struct {
+	Height int64 // Confirmation height, or UnconfirmedHeight
+	TxID   string
+}
+
+// ParseHistoryCursor parses a cursor in the format returned by
+// HistoryCursor.String, ex: 654321:<txid>.
+func ParseHistoryCursor(s string) (*HistoryCursor, error) {
+	height, txid, found := strings.Cut(s, ":")
+	if !found || txid == "" {
+		return nil, fmt.Errorf("invalid cursor '%s'", s)
+	}
+
+	h, err := strconv.ParseInt(height, 10, 64)
+	if err != nil || h < 0 {
+		return nil, fmt.Errorf("invalid cursor '%s'", s)
+	}
+
+	return &HistoryCursor{Height: h, TxID: txid}, nil
+}
+
+func (c HistoryCursor) String() string {
+	return fmt.Sprintf("%d:%s", c.Height, c.TxID)
+}
+
+// Before reports whether c comes before other in the history order.
+func (c HistoryCursor) Before(other HistoryCursor) bool {
+	if c.Height != other.Height {
+		return c.Height > other.Height
+	}
+
+	return c.TxID < other.TxID
+}
+
+// HistoryPage selects a page of transaction history.
+type HistoryPage struct {
+	After *HistoryCursor // Cursor of the last transaction of the previous page, nil for the first page
+	Limit int            // Maximum number of transactions in the page
+}
+
+// WalletSyncResult models the changes to the wallet transactions since a
+// given block.
+type WalletSyncResult struct {
+	Transactions []Transaction `json:"transactions"` // Transactions added since the block, including unconfirmed ones
+	Removed      []string      `json:"removed"`      // IDs of transactions removed from the chain by a reorg
+	LastBlock    string        `json:"lastblock"`    // Block to sync from in the next call
+}
@@ -0,0 +1,67 @@
+package types
+
+import "testing"
+
+func TestParseHistoryCursor(t *testing.T) {
+	const txid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+	tests := []struct {
+		cursor string
+		want   *HistoryCursor
+	}{
+		{cursor: "654321:" + txid, want: &HistoryCursor{Height: 654321, TxID: txid}},
+		{cursor: "0:" + txid, want: &HistoryCursor{Height: 0, TxID: txid}},
+		{cursor: "9223372036854775807:" + txid, want: &HistoryCursor{Height: UnconfirmedHeight, TxID: txid}},
+		{cursor: ""},
+		{cursor: "654321"},
+		{cursor: "654321:"},
+		{cursor: ":" + txid},
+		{cursor: "-1:" + txid},
+		{cursor: "abc:" + txid},
+		{cursor: "9223372036854775808:" + txid},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHistoryCursor(tt.cursor)
+
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("ParseHistoryCursor(%q) = %+v, want an error", tt.cursor, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("ParseHistoryCursor(%q) error = %v", tt.cursor, err)
+		}
+
+		if *got != *tt.want {
+			t.Errorf("ParseHistoryCursor(%q) = %+v, want %+v", tt.cursor, got, tt.want)
+		}
+
+		// The cursor is returned as is by String.
+		if got.String() != tt.cursor {
+			t.Errorf("ParseHistoryCursor(%q).String() = %q", tt.cursor, got.String())
+		}
+	}
+}
+
+func TestHistoryCursorBefore(t *testing.T) {
+	tests := []struct {
+		c, other HistoryCursor
+		want     bool
+	}{
+		{c: HistoryCursor{Height: UnconfirmedHeight, TxID: "b"}, other: HistoryCursor{Height: 100, TxID: "a"}, want: true},
+		{c: HistoryCursor{Height: 100, TxID: "a"}, other: HistoryCursor{Height: UnconfirmedHeight, TxID: "b"}},
+		{c: HistoryCursor{Height: 101, TxID: "z"}, other: HistoryCursor{Height: 100, TxID: "a"}, want: true},
+		{c: HistoryCursor{Height: 100, TxID: "a"}, other: HistoryCursor{Height: 100, TxID: "b"}, want: true},
+		{c: HistoryCursor{Height: 100, TxID: "b"}, other: HistoryCursor{Height: 100, TxID: "a"}},
+		{c: HistoryCursor{Height: 100, TxID: "a"}, other: HistoryCursor{Height: 100, TxID: "a"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.Before(tt.other); got != tt.want {
+			t.Errorf("%s.Before(%s) = %v, want %v", tt.c, tt.other, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package types
+
+import "github.com/btcsuite/btcd/btcutil"
+
+// MempoolInfo models the data from the getmempoolinfo command.
+type MempoolInfo struct {
+	Loaded        bool    `json:"loaded"`        // True if the mempool is fully loaded
+	Size          int64   `json:"size"`          // Number of transactions
+	Bytes         int64   `json:"bytes"`         // Sum of all virtual transaction sizes
+	Usage         int64   `json:"usage"`         // Total memory usage of the mempool, in bytes
+	MaxMempool    int64   `json:"maxmempool"`    // Maximum memory usage of the mempool, in bytes
+	MempoolMinFee float64 `json:"mempoolminfee"` // Minimum fee rate for a transaction to be accepted, in BTC/kvB
+	MinRelayTxFee float64 `json:"minrelaytxfee"` // Minimum relay fee rate, in BTC/kvB
+}
+
+// MempoolEntry models the data from the getmempoolentry command.
+type MempoolEntry struct {
+	VSize             int64            `json:"vsize"`              // Virtual transaction size
+	Weight            int64            `json:"weight"`             // Transaction weight
+	Time              int64            `json:"time"`               // UNIX timestamp when the transaction entered the mempool
+	Height            int64            `json:"height"`             // Block height when the transaction entered the mempool
+	DescendantCount   int64            `json:"descendantcount"`    // Number of in-mempool descendants, including itself
+	DescendantSize    int64            `json:"descendantsize"`     // Virtual size of in-mempool descendants, including itself
+	AncestorCount     int64            `json:"ancestorcount"`      // Number of in-mempool ancestors, including itself
+	AncestorSize      int64            `json:"ancestorsize"`       // Virtual size of in-mempool ancestors, including itself
+	Fees              MempoolEntryFees `json:"fees"`               // Fees, in BTC
+	Depends           []string         `json:"depends"`            // Unconfirmed transactions used as inputs
+	SpentBy           []string         `json:"spentby"`            // Unconfirmed transactions spending outputs
+	BIP125Replaceable bool             `json:"bip125-replaceable"` // Whether the transaction signals opt-in RBF
+}
+
+// MempoolEntryFees models the fees of a MempoolEntry, in BTC.
+type MempoolEntryFees struct {
+	Base       float64 `json:"base"`       // Transaction fee
+	Modified   float64 `json:"modified"`   // Transaction fee with fee deltas used for mining priority
+	Ancestor   float64 `json:"ancestor"`   // Modified fees of in-mempool ancestors, including itself
+	Descendant float64 `json:"descendant"` // Modified fees of in-mempool descendants, including itself
+}
+
+// PackageAcceptResult models whether a package of transactions would be
+// accepted in the mempool, as a whole and per transaction.
+type PackageAcceptResult struct {
+	Allowed        bool              `json:"allowed"`                 // Whether every transaction would be accepted
+	PackageFeeRate *float64          `json:"package_fee_rate"`        // In sat/vB, nil unless allowed
+	PackageError   string            `json:"package_error,omitempty"` // Reason for rejecting the whole package
+	Transactions   []PackageTxResult `json:"transactions"`
+}
+
+// PackageTxResult models whether a transaction of a package would be
+// accepted in the mempool.
+type PackageTxResult struct {
+	TxID         string          `json:"txid"`
+	WTxID        string          `json:"wtxid"`
+	Allowed      bool            `json:"allowed"`
+	VSize        int64           `json:"vsize"`
+	Fee          *btcutil.Amount `json:"fee,omitempty"` // In satoshis, only if the transaction was tested
+	RejectReason string          `json:"reject_reason,omitempty"`
+}
@@ -0,0 +1,54 @@
+package types
+
+// NetworkStats models chain health statistics about the network that the
+// node is connected to.
+type NetworkStats struct {
+	Height               int64   `json:"height"`                 // Height of the chain tip
+	Difficulty           float64 `json:"difficulty"`             // Proof-of-work difficulty of the chain tip
+	HashRate             float64 `json:"hashrate"`               // Estimated network hashes per second
+	RetargetHeight       int64   `json:"retarget_height"`        // Height of the next difficulty adjustment
+	BlocksUntilRetarget  int64   `json:"blocks_until_retarget"`  // RetargetHeight - Height
+	AverageBlockInterval float64 `json:"average_block_interval"` // In seconds, over the current retarget period
+	EstimatedRetargetAt  string  `json:"estimated_retarget_at"`  // RFC3339 format
+}
+
+// NodeInfo models the uptime and peer connectivity of the connected node.
+type NodeInfo struct {
+	Uptime         int64 `json:"uptime"`          // Seconds since bitcoind was started
+	NetworkActive  bool  `json:"network_active"`  // Whether P2P networking is enabled
+	Connections    int64 `json:"connections"`     // Total number of peer connections
+	ConnectionsIn  int64 `json:"connections_in"`  // Number of inbound peer connections
+	ConnectionsOut int64 `json:"connections_out"` // Number of outbound peer connections
+}
+
+// PeerInfo models a peer connected to the node.
+type PeerInfo struct {
+	ID             int32   `json:"id"`
+	Address        string  `json:"address"`
+	Inbound        bool    `json:"inbound"`
+	Version        uint32  `json:"version"`         // Protocol version advertised by the peer
+	SubVersion     string  `json:"subversion"`      // User agent, ex: /Satoshi:25.0.0/
+	Services       string  `json:"services"`        // Hex-encoded service flags
+	PingTime       float64 `json:"ping_time"`       // Last ping time in seconds, 0 if unknown
+	StartingHeight int32   `json:"starting_height"` // Height of the peer when the connection started
+	SyncedHeaders  int32   `json:"synced_headers"`  // Last header in common with the peer, -1 if unknown
+	SyncedBlocks   int32   `json:"synced_blocks"`   // Last block in common with the peer, -1 if unknown
+}
+
+// Peers models a page of the peers connected to the node.
+type Peers struct {
+	Total int        `json:"total"` // Total number of connected peers
+	Peers []PeerInfo `json:"peers"`
+}
+
+// VersionInfo models the versions of SatStack and of the connected node.
+type VersionInfo struct {
+	Version        string `json:"version"`         // SatStack version, ex: v0.19.1
+	GitCommit      string `json:"git_commit"`      // Empty for development builds
+	Build          string `json:"build"`           // "release" for production builds
+	BuildTime      string `json:"build_time"`      // Empty for development builds
+	GoVersion      string `json:"go_version"`      // Go runtime used to compile SatStack
+	OsArch         string `json:"os_arch"`         // ex: linux amd64
+	NodeVersion    int32  `json:"node_version"`    // Version of the connected bitcoind, ex: 220000
+	NodeSubversion string `json:"node_subversion"` // User agent of the connected bitcoind, ex: /Satoshi:22.0.0/
+}
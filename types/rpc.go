@@ -0,0 +1,21 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// RPCCall models a call to a Bitcoin Core RPC method, issued through the RPC
+// passthrough.
+type RPCCall struct {
+	Method string            `json:"method" binding:"required"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// RPCResult models the result of an RPCCall. Exactly one of Result and Error
+// is set.
+type RPCResult struct {
+	Result json.RawMessage   `json:"result"`
+	Error  *btcjson.RPCError `json:"error"`
+}
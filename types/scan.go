@@ -0,0 +1,22 @@
+package types
+
+import "github.com/btcsuite/btcd/btcutil"
+
+// ScanResult models the UTXOs matching a set of descriptors in the UTXO set
+// of the connected node, as found by scantxoutset.
+type ScanResult struct {
+	Height      int64          `json:"height"`       // Height of the UTXO set that was scanned
+	BestBlock   string         `json:"best_block"`   // Hash of the block at Height
+	TotalAmount btcutil.Amount `json:"total_amount"` // Sum of the amounts of all UTXOs
+	Unspents    []ScanUnspent  `json:"unspents"`
+}
+
+// ScanUnspent models a single UTXO found by scantxoutset.
+type ScanUnspent struct {
+	TxID         string         `json:"txid"`
+	Vout         uint32         `json:"vout"`
+	ScriptPubKey string         `json:"script_pub_key"`
+	Descriptor   string         `json:"descriptor"` // Descriptor of the matched output script
+	Amount       btcutil.Amount `json:"amount"`
+	Height       int64          `json:"height"` // Height of the block containing the UTXO
+}
@@ -0,0 +1,46 @@
+package types
+
+// ScriptType classifies the output script of a transaction output.
+type ScriptType string
+
+const (
+	ScriptTypeP2PK           ScriptType = "p2pk"
+	ScriptTypeP2PKH          ScriptType = "p2pkh"
+	ScriptTypeP2SH           ScriptType = "p2sh"
+	ScriptTypeP2WPKH         ScriptType = "p2wpkh"
+	ScriptTypeP2WSH          ScriptType = "p2wsh"
+	ScriptTypeP2TR           ScriptType = "p2tr"
+	ScriptTypeMultisig       ScriptType = "multisig"
+	ScriptTypeNullData       ScriptType = "nulldata"
+	ScriptTypeWitnessUnknown ScriptType = "witness_unknown"
+	ScriptTypeNonStandard    ScriptType = "nonstandard"
+
+	// ScriptTypeUnknown is used for script types that SatStack does not know
+	// of, for ex those introduced by newer versions of Bitcoin Core.
+	ScriptTypeUnknown ScriptType = "unknown"
+)
+
+// coreScriptTypes maps the scriptPubKey types reported by Bitcoin Core (and
+// btcd) to a ScriptType.
+var coreScriptTypes = map[string]ScriptType{
+	"pubkey":                ScriptTypeP2PK,
+	"pubkeyhash":            ScriptTypeP2PKH,
+	"scripthash":            ScriptTypeP2SH,
+	"witness_v0_keyhash":    ScriptTypeP2WPKH,
+	"witness_v0_scripthash": ScriptTypeP2WSH,
+	"witness_v1_taproot":    ScriptTypeP2TR,
+	"multisig":              ScriptTypeMultisig,
+	"nulldata":              ScriptTypeNullData,
+	"witness_unknown":       ScriptTypeWitnessUnknown,
+	"nonstandard":           ScriptTypeNonStandard,
+}
+
+// ParseScriptType converts a scriptPubKey type, as reported by Bitcoin Core,
+// to a ScriptType. Unrecognized types map to ScriptTypeUnknown.
+func ParseScriptType(coreType string) ScriptType {
+	if scriptType, ok := coreScriptTypes[coreType]; ok {
+		return scriptType
+	}
+
+	return ScriptTypeUnknown
+}
@@ -0,0 +1,23 @@
+package types
+
+import "github.com/btcsuite/btcd/btcutil"
+
+// SupplyReport models the result of a circulating supply check performed
+// against the UTXO set of the connected node.
+type SupplyReport struct {
+	Height         int64          `json:"height"`
+	ExpectedSupply btcutil.Amount `json:"expected_supply"` // Sum of the block subsidies from block 1 to Height included
+	ActualSupply   btcutil.Amount `json:"actual_supply"`   // Total amount in the UTXO set at Height
+	Difference     btcutil.Amount `json:"difference"`      // ActualSupply - ExpectedSupply
+	Mismatch       bool           `json:"mismatch"`        // Surplus beyond dust, indicating inflation
+}
+
+// SubsidyInfo models the block subsidy at a given height, along with the
+// halving epoch it belongs to.
+type SubsidyInfo struct {
+	Height             int64          `json:"height"`
+	Subsidy            btcutil.Amount `json:"subsidy"`              // Block subsidy in satoshis, excluding fees
+	Epoch              int64          `json:"epoch"`                // Number of halvings that occurred before Height
+	NextHalvingHeight  int64          `json:"next_halving_height"`  // Height of the first block of the next epoch
+	BlocksUntilHalving int64          `json:"blocks_until_halving"` // NextHalvingHeight - Height
+}
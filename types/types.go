@@ -17,6 +17,23 @@ type UTXOData struct {
 // Convenience type; for limited use only.
 type UTXOs map[OutputIdentifier]UTXOData
 
+// UnspentOutput models a wallet UTXO, as returned by the listunspent RPC.
+type UnspentOutput struct {
+	OutputHash    string         `json:"output_hash"`   // Transaction ID of the UTXO
+	OutputIndex   uint32         `json:"output_index"`  // Index of the UTXO in the transaction
+	Address       string         `json:"address"`       // Address of the UTXO; can be empty
+	Value         btcutil.Amount `json:"value"`         // Value of the UTXO in satoshis
+	ScriptHex     string         `json:"script_hex"`    // Hex-encoded script
+	Confirmations int64          `json:"confirmations"` // 0 for unconfirmed UTXOs
+}
+
+// Balance models the aggregate value of a set of UTXOs.
+type Balance struct {
+	Confirmed   btcutil.Amount `json:"confirmed"`   // Sum of UTXOs with at least 1 confirmation
+	Unconfirmed btcutil.Amount `json:"unconfirmed"` // Sum of UTXOs with 0 confirmations
+	UTXOCount   int            `json:"utxo_count"`
+}
+
 // Input models data corresponding to transaction inputs.
 type Input struct {
 	Coinbase    string          `json:"coinbase,omitempty"`         // [coinbase] The coinbase encoded as hex
@@ -36,16 +53,22 @@ type Output struct {
 	Value       *btcutil.Amount `json:"value,omitempty"`        // Value of output in satoshis
 	ScriptHex   string          `json:"script_hex"`             // Hex-encoded script
 	Address     string          `json:"address,omitempty"`      // Address of the UTXO; can be empty
+	ScriptType  ScriptType      `json:"script_type,omitempty"`  // Type of the output script, for ex p2wpkh
+	Unspendable bool            `json:"unspendable,omitempty"`  // [OP_RETURN] Data output, that can never be spent
+	DataHex     *string         `json:"data_hex,omitempty"`     // [OP_RETURN] Hex-encoded data carried by the output
+	Data        *string         `json:"data,omitempty"`         // [OP_RETURN] Data carried by the output, if printable UTF-8 text
 }
 
 // Block models data corresponding to a block, but with limited information.
 // It is used to represent minimal information of the block containing the given
 // transaction.
 type Block struct {
-	Hash         string    `json:"hash"`          // 0x prefixed
-	Height       int64     `json:"height"`        // integer
-	Time         string    `json:"time"`          // RFC3339 format
-	Transactions *[]string `json:"txs,omitempty"` // optional list of 0x prefixed transaction IDs
+	Hash         string    `json:"hash"`                  // 0x prefixed
+	Height       int64     `json:"height"`                // integer
+	Time         string    `json:"time"`                  // RFC3339 format
+	MedianTime   string    `json:"median_time,omitempty"` // RFC3339 format, median-time-past (BIP113)
+	Transactions *[]string `json:"txs,omitempty"`         // optional list of 0x prefixed transaction IDs
+	TxCount      *int64    `json:"tx_count,omitempty"`    // optional number of transactions
 }
 
 // BlockWithTransactions is a struct that embeds Block, but also contains
@@ -55,6 +78,11 @@ type BlockWithTransactions struct {
 	Transactions []string `json:"txs"` // 0x prefixed
 }
 
+// OrphanedConfirmations is the number of confirmations of transactions that
+// conflict with the active chain, for ex since their block was reorganized
+// out and a conflicting transaction was confirmed instead.
+const OrphanedConfirmations = -1
+
 // Transaction represents the principal type to model the response of the GetTransaction handler.
 type Transaction struct {
 	ID            string          `json:"id"` // only in v3 explorer
@@ -63,7 +91,8 @@ type Transaction struct {
 	LockTime      uint32          `json:"lock_time"`
 	Fees          *btcutil.Amount `json:"fees"`
 	Amount        *btcutil.Amount `json:"amount,omitempty"` // legacy field for v2 explorer
-	Confirmations uint64          `json:"confirmations"`
+	Confirmations int64           `json:"confirmations"`    // 0 if unconfirmed, OrphanedConfirmations if orphaned
+	Replaceable   bool            `json:"replaceable"`      // BIP125 opt-in RBF; always false once confirmed
 	Inputs        []Input         `json:"inputs"`
 	Outputs       []Output        `json:"outputs"`
 	Block         *Block          `json:"block"`
@@ -72,4 +101,5 @@ type Transaction struct {
 type Addresses struct {
 	Truncated    bool          `json:"truncated"`
 	Transactions []Transaction `json:"txs"`
+	Next         *string       `json:"next,omitempty"` // Cursor of the next page, if paginated and more transactions exist
 }
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -62,6 +65,31 @@ func ToStringPointer(value string) *string {
 	return btcjson.String(value)
 }
 
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the given request ID, used to
+// correlate the logs emitted while serving a single HTTP request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string if
+// there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID generates a random 64-bit request ID, encoded as hex.
+func NewRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b[:])
+}
+
 const (
 	day  = time.Minute * 60 * 24
 	year = 365 * day
@@ -71,7 +99,8 @@ const (
 // Taken from: https://gist.github.com/harshavardhana/327e0577c4fed9211f65
 //
 // Example:
-// 	HumanizeDuration(time.Duration())
+//
+//	HumanizeDuration(time.Duration())
 func HumanizeDuration(d time.Duration) string {
 	if d < day {
 		return d.String()
@@ -14,6 +14,9 @@ const Version = "v0.19.1-knots"
 // Build indicates whether the build was a development or a production build.
 var Build string
 
+// BuildTime returns the time at which the binary was compiled. This will be filled in by the compiler.
+var BuildTime string
+
 // GoVersion returns the version of the go runtime used to compile the binary
 var GoVersion = runtime.Version()
 